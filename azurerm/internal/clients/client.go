@@ -0,0 +1,28 @@
+package clients
+
+import (
+	"context"
+
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/common"
+	compute "github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/compute/client"
+	keyvault "github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/keyvault/client"
+	network "github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/services/network/client"
+)
+
+type Client struct {
+	StopContext context.Context
+
+	Compute  *compute.Client
+	KeyVault *keyvault.Client
+	Network  *network.Client
+}
+
+func (client *Client) Build(ctx context.Context, o *common.ClientOptions) error {
+	client.StopContext = ctx
+
+	client.Compute = compute.NewClient(o)
+	client.KeyVault = keyvault.NewClient(o)
+	client.Network = network.NewClient(o)
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package common
+
+import (
+	"github.com/Azure/go-autorest/autorest"
+)
+
+// ClientOptions holds the shared configuration used to construct each
+// service package's SDK clients (base URI, subscription and authorizers).
+type ClientOptions struct {
+	SubscriptionId            string
+	ResourceManagerEndpoint   string
+	ResourceManagerAuthorizer autorest.Authorizer
+
+	// KeyVaultAuthorizer authorizes requests to a Key Vault's own data-plane API (e.g. `https://vault.azure.net`),
+	// which is a distinct audience/resource from the Resource Manager API the other clients talk to.
+	KeyVaultAuthorizer autorest.Authorizer
+}
+
+// ConfigureClient applies the given authorizer to a newly constructed SDK
+// client; the base URI and subscription are set by the client's constructor.
+func (o ClientOptions) ConfigureClient(c *autorest.Client, authorizer autorest.Authorizer) {
+	c.Authorizer = authorizer
+}
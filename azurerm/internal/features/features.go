@@ -0,0 +1,18 @@
+package features
+
+import "os"
+
+// ShouldResourcesBeImported controls whether resources check for an existing Azure resource with the
+// same ID before creating one, turning what would otherwise be a silent adopt/overwrite into an explicit
+// "already exists" error - this is a provider-level feature, not something each resource opts into.
+func ShouldResourcesBeImported() bool {
+	return os.Getenv("ARM_PROVIDER_SKIP_RESOURCE_IMPORT_CHECK") == ""
+}
+
+// SkipImageValidation opts every resource embedding `SourceImageReferenceSchema` out of the plan-time
+// `source_image_reference` existence/deprecation check against the platform images API - this is a
+// provider-level opt-out since it's typically needed for an entire offline/air-gapped environment where
+// the platform images API isn't reachable, rather than a single resource.
+func SkipImageValidation() bool {
+	return os.Getenv("ARM_PROVIDER_SKIP_IMAGE_VALIDATION") != ""
+}
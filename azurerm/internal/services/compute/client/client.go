@@ -0,0 +1,34 @@
+package client
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/common"
+)
+
+type Client struct {
+	DedicatedHostGroupsClient *compute.DedicatedHostGroupsClient
+	DisksClient               *compute.DisksClient
+	VMClient                  *compute.VirtualMachinesClient
+	VMImageClient             *compute.VirtualMachineImagesClient
+}
+
+func NewClient(o *common.ClientOptions) *Client {
+	dedicatedHostGroupsClient := compute.NewDedicatedHostGroupsClientWithBaseURI(o.ResourceManagerEndpoint, o.SubscriptionId)
+	o.ConfigureClient(&dedicatedHostGroupsClient.Client, o.ResourceManagerAuthorizer)
+
+	disksClient := compute.NewDisksClientWithBaseURI(o.ResourceManagerEndpoint, o.SubscriptionId)
+	o.ConfigureClient(&disksClient.Client, o.ResourceManagerAuthorizer)
+
+	vmClient := compute.NewVirtualMachinesClientWithBaseURI(o.ResourceManagerEndpoint, o.SubscriptionId)
+	o.ConfigureClient(&vmClient.Client, o.ResourceManagerAuthorizer)
+
+	vmImageClient := compute.NewVirtualMachineImagesClientWithBaseURI(o.ResourceManagerEndpoint, o.SubscriptionId)
+	o.ConfigureClient(&vmImageClient.Client, o.ResourceManagerAuthorizer)
+
+	return &Client{
+		DedicatedHostGroupsClient: &dedicatedHostGroupsClient,
+		DisksClient:               &disksClient,
+		VMClient:                  &vmClient,
+		VMImageClient:             &vmImageClient,
+	}
+}
@@ -1,11 +1,13 @@
 package compute
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/customdiff"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
@@ -36,6 +38,11 @@ func resourceLinuxVirtualMachine() *schema.Resource {
 			return err
 		}),
 
+		CustomizeDiff: customdiff.All(
+			sourceImageReferenceCustomizeDiff,
+			secretsCustomizeDiff,
+		),
+
 		Timeouts: &schema.ResourceTimeout{
 			Create: schema.DefaultTimeout(45 * time.Minute),
 			Read:   schema.DefaultTimeout(5 * time.Minute),
@@ -108,13 +115,15 @@ func resourceLinuxVirtualMachine() *schema.Resource {
 				ValidateFunc: azure.ValidateResourceID,
 				// TODO: confirm if the casing is also broken for this API
 				ConflictsWith: []string{
-					// TODO: "virtual_machine_scale_set_id"
+					"virtual_machine_scale_set_id",
 					"zone",
 				},
 			},
 
 			"boot_diagnostics": bootDiagnosticsSchema(),
 
+			"identity": virtualMachineIdentitySchema(),
+
 			"computer_name": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -129,6 +138,27 @@ func resourceLinuxVirtualMachine() *schema.Resource {
 
 			"custom_data": base64.OptionalSchema(),
 
+			// whilst the `azurerm_virtual_machine` resource always deleted the OS Disk on destroy (and never the
+			// NICs) by default, we expose both as opt-outs/opt-ins here to give users more control
+			"delete_os_disk_on_deletion": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
+			"delete_network_interfaces_on_deletion": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  false,
+			},
+
+			"dedicated_host_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: azure.ValidateResourceID,
+			},
+
 			"disable_password_authentication": {
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -143,10 +173,61 @@ func resourceLinuxVirtualMachine() *schema.Resource {
 				ValidateFunc: validation.StringInSlice([]string{
 					string(compute.Low), // TODO: remove me
 					string(compute.Regular),
-					// TODO: spot
+					string(compute.Spot),
+				}, false),
+			},
+
+			"eviction_policy": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(compute.Deallocate),
+					string(compute.Delete),
 				}, false),
 			},
 
+			"max_bid_price": {
+				Type:         schema.TypeFloat,
+				Optional:     true,
+				Default:      -1,
+				ValidateFunc: validation.FloatAtLeast(-1),
+			},
+
+			// required when deploying a third-party/marketplace image that requires terms to be accepted
+			// (e.g. `azurerm_marketplace_agreement`) - hashed as a `TypeSet` so re-ordering the fields
+			// (which Terraform sometimes does on refresh) doesn't produce a spurious diff
+			"plan": {
+				Type:     schema.TypeSet,
+				Optional: true,
+				ForceNew: true,
+				MaxItems: 1,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validate.NoEmptyStrings,
+						},
+
+						"publisher": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validate.NoEmptyStrings,
+						},
+
+						"product": {
+							Type:         schema.TypeString,
+							Required:     true,
+							ForceNew:     true,
+							ValidateFunc: validate.NoEmptyStrings,
+						},
+					},
+				},
+			},
+
 			"provision_vm_agent": {
 				Type:     schema.TypeBool,
 				Optional: true,
@@ -167,16 +248,40 @@ func resourceLinuxVirtualMachine() *schema.Resource {
 
 			"secret": linuxSecretSchema(),
 
+			"shared_image": SharedImageReferenceSchema(),
+
 			"source_image_id": {
-				Type:         schema.TypeString,
-				Optional:     true,
-				ValidateFunc: azure.ValidateResourceID,
+				Type:          schema.TypeString,
+				Optional:      true,
+				ForceNew:      true,
+				ValidateFunc:  azure.ValidateResourceID,
+				ConflictsWith: []string{"source_image_reference", "shared_image"},
 			},
 
 			"source_image_reference": SourceImageReferenceSchema(),
 
+			// whilst updating `size` or `network_interface_ids` requires the VM to be deallocated first, by default we
+			// restart it once the update's completed - set this to `false` to leave the VM powered off after such an update
+			"restart_after_update": {
+				Type:     schema.TypeBool,
+				Optional: true,
+				Default:  true,
+			},
+
 			"tags": tags.Schema(),
 
+			// attaches this standalone VM to a Flexible-orchestration VM Scale Set, which is Azure's
+			// recommended way to build HA groups of VMs without an Availability Set
+			"virtual_machine_scale_set_id": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				ForceNew:     true,
+				ValidateFunc: azure.ValidateResourceID,
+				ConflictsWith: []string{
+					"availability_set_id",
+				},
+			},
+
 			"zone": {
 				Type:     schema.TypeString,
 				Optional: true,
@@ -258,6 +363,8 @@ func resourceLinuxVirtualMachineCreate(d *schema.ResourceData, meta interface{})
 	}
 	disablePasswordAuthentication := d.Get("disable_password_authentication").(bool)
 	location := azure.NormalizeLocation(d.Get("location").(string))
+	evictionPolicy := d.Get("eviction_policy").(string)
+	maxBidPrice := d.Get("max_bid_price").(float64)
 	priority := d.Get("priority").(string)
 	provisionVMAgent := d.Get("provision_vm_agent").(bool)
 	size := d.Get("size").(string)
@@ -270,11 +377,15 @@ func resourceLinuxVirtualMachineCreate(d *schema.ResourceData, meta interface{})
 	osDisk := ExpandVirtualMachineOSDisk(osDiskRaw, compute.Linux)
 
 	secretsRaw := d.Get("secret").([]interface{})
-	secrets := expandLinuxSecrets(secretsRaw)
+	secrets, err := expandLinuxSecrets(ctx, meta.(*clients.Client).KeyVault.ManagementClient, secretsRaw)
+	if err != nil {
+		return fmt.Errorf("expanding `secret`: %+v", err)
+	}
 
 	sourceImageReferenceRaw := d.Get("source_image_reference").([]interface{})
+	sharedImageRaw := d.Get("shared_image").([]interface{})
 	sourceImageId := d.Get("source_image_id").(string)
-	sourceImageReference, err := ExpandSourceImageReference(sourceImageReferenceRaw, sourceImageId)
+	sourceImageReference, err := ExpandSourceImageReference(sourceImageReferenceRaw, sharedImageRaw, sourceImageId)
 	if err != nil {
 		return err
 	}
@@ -282,6 +393,12 @@ func resourceLinuxVirtualMachineCreate(d *schema.ResourceData, meta interface{})
 	sshKeysRaw := d.Get("admin_ssh_key").(*schema.Set).List()
 	sshKeys := ExpandSSHKeys(sshKeysRaw)
 
+	identityRaw := d.Get("identity").([]interface{})
+	identity, err := expandVirtualMachineIdentity(identityRaw)
+	if err != nil {
+		return fmt.Errorf("expanding `identity`: %+v", err)
+	}
+
 	params := compute.VirtualMachine{
 		Name:     utils.String(name),
 		Location: utils.String(location),
@@ -319,39 +436,57 @@ func resourceLinuxVirtualMachineCreate(d *schema.ResourceData, meta interface{})
 			AdditionalCapabilities: additionalCapabilities,
 			DiagnosticsProfile:     bootDiagnostics,
 
-			// conflicts with availability set id
-			//VirtualMachineScaleSet: nil,
-
-			// Optional
-			//BillingProfile: nil,
-			//EvictionPolicy: "",
-
-			// Optional - dedicated_host_id
-			Host: nil,
-
 			// only applicable to Windows
 			//LicenseType:             utils.String(licenseType),
 		},
-		Tags: tags.Expand(t),
-		// TODO: optionally populated
-		//Identity:                 nil,
-		//Plan:                     nil,
+		Identity: identity,
+		Tags:     tags.Expand(t),
+	}
+
+	planRaw := d.Get("plan").(*schema.Set).List()
+	if len(planRaw) > 0 {
+		params.Plan = expandPlan(planRaw)
 	}
 
 	if !provisionVMAgent && allowExtensionOperations {
 		return fmt.Errorf("`allow_extension_operations` cannot be set to `true` when `provision_vm_agent` is set to `false`")
 	}
 
+	_, hasAvailabilitySet := d.GetOk("availability_set_id")
+	isSpot, err := validateSpotVirtualMachineConfiguration(priority, evictionPolicy, maxBidPrice, hasAvailabilitySet)
+	if err != nil {
+		return err
+	}
+
+	if isSpot {
+		params.VirtualMachineProperties.BillingProfile = &compute.BillingProfile{
+			MaxPrice: utils.Float(maxBidPrice),
+		}
+		params.VirtualMachineProperties.EvictionPolicy = compute.VirtualMachineEvictionPolicyTypes(evictionPolicy)
+	}
+
 	if v, ok := d.GetOk("availability_set_id"); ok {
 		params.AvailabilitySet = &compute.SubResource{
 			ID: utils.String(v.(string)),
 		}
 	}
 
+	if v, ok := d.GetOk("virtual_machine_scale_set_id"); ok {
+		params.VirtualMachineScaleSet = &compute.SubResource{
+			ID: utils.String(v.(string)),
+		}
+	}
+
 	if v, ok := d.GetOk("custom_data"); ok {
 		params.OsProfile.CustomData = utils.String(v.(string))
 	}
 
+	if v, ok := d.GetOk("dedicated_host_id"); ok {
+		params.Host = &compute.SubResource{
+			ID: utils.String(v.(string)),
+		}
+	}
+
 	if v, ok := d.GetOk("proximity_placement_group_id"); ok {
 		params.ProximityPlacementGroup = &compute.SubResource{
 			ID: utils.String(v.(string)),
@@ -426,6 +561,14 @@ func resourceLinuxVirtualMachineRead(d *schema.ResourceData, meta interface{}) e
 		d.Set("location", azure.NormalizeLocation(*resp.Location))
 	}
 
+	if err := d.Set("plan", flattenPlan(resp.Plan)); err != nil {
+		return fmt.Errorf("Error setting `plan`: %+v", err)
+	}
+
+	if err := d.Set("identity", flattenVirtualMachineIdentity(resp.Identity)); err != nil {
+		return fmt.Errorf("Error setting `identity`: %+v", err)
+	}
+
 	if props := resp.VirtualMachineProperties; props != nil {
 		if err := d.Set("additional_capabilities", flattenVirtualMachineAdditionalCapabilities(props.AdditionalCapabilities)); err != nil {
 			return fmt.Errorf("Error setting `additional_capabilities`: %+v", err)
@@ -437,6 +580,12 @@ func resourceLinuxVirtualMachineRead(d *schema.ResourceData, meta interface{}) e
 		}
 		d.Set("availability_set_id", availabilitySetId)
 
+		virtualMachineScaleSetId := ""
+		if props.VirtualMachineScaleSet != nil && props.VirtualMachineScaleSet.ID != nil {
+			virtualMachineScaleSetId = *props.VirtualMachineScaleSet.ID
+		}
+		d.Set("virtual_machine_scale_set_id", virtualMachineScaleSetId)
+
 		if err := d.Set("boot_diagnostics", flattenBootDiagnostics(props.DiagnosticsProfile)); err != nil {
 			return fmt.Errorf("Error setting `boot_diagnostics`: %+v", err)
 		}
@@ -451,11 +600,11 @@ func resourceLinuxVirtualMachineRead(d *schema.ResourceData, meta interface{}) e
 			}
 		}
 
-		//dedicatedHostId := ""
-		//if props.Host != nil && props.Host.ID != nil {
-		//	dedicatedHostId = *props.Host.ID
-		//}
-		//d.Set("dedicated_host_id", dedicatedHostId)
+		dedicatedHostId := ""
+		if props.Host != nil && props.Host.ID != nil {
+			dedicatedHostId = *props.Host.ID
+		}
+		d.Set("dedicated_host_id", dedicatedHostId)
 
 		if profile := props.OsProfile; profile != nil {
 			d.Set("admin_username", profile.AdminUsername)
@@ -475,12 +624,20 @@ func resourceLinuxVirtualMachineRead(d *schema.ResourceData, meta interface{}) e
 				}
 			}
 
-			if err := d.Set("secret", flattenLinuxSecrets(profile.Secrets)); err != nil {
+			if err := d.Set("secret", flattenLinuxSecrets(profile.Secrets, d.Get("secret").([]interface{}))); err != nil {
 				return fmt.Errorf("Error setting `secret`: %+v", err)
 			}
 		}
 
 		d.Set("priority", string(props.Priority))
+		d.Set("eviction_policy", string(props.EvictionPolicy))
+
+		maxBidPrice := float64(-1)
+		if props.BillingProfile != nil && props.BillingProfile.MaxPrice != nil {
+			maxBidPrice = *props.BillingProfile.MaxPrice
+		}
+		d.Set("max_bid_price", maxBidPrice)
+
 		proximityPlacementGroupId := ""
 		if props.ProximityPlacementGroup != nil && props.ProximityPlacementGroup.ID != nil {
 			proximityPlacementGroupId = *props.ProximityPlacementGroup.ID
@@ -492,15 +649,24 @@ func resourceLinuxVirtualMachineRead(d *schema.ResourceData, meta interface{}) e
 				return fmt.Errorf("Error settings `os_disk`: %+v", err)
 			}
 
+			sourceImageReference := FlattenSourceImageReference(profile.ImageReference)
+			if err := d.Set("source_image_reference", sourceImageReference); err != nil {
+				return fmt.Errorf("Error setting `source_image_reference`: %+v", err)
+			}
+
+			sharedImage := FlattenSharedImageReference(profile.ImageReference)
+			if err := d.Set("shared_image", sharedImage); err != nil {
+				return fmt.Errorf("Error setting `shared_image`: %+v", err)
+			}
+
+			// the image ID is also the shape used by `shared_image` (and, in principle, `source_image_reference`)
+			// - only surface it as `source_image_id` when neither of those claimed it, otherwise a VM created from
+			// a Shared/Community Gallery image would show a diff on this (non-Computed, ForceNew) field forever
 			var storageImageId string
-			if profile.ImageReference != nil && profile.ImageReference.ID != nil {
+			if len(sourceImageReference) == 0 && len(sharedImage) == 0 && profile.ImageReference != nil && profile.ImageReference.ID != nil {
 				storageImageId = *profile.ImageReference.ID
 			}
 			d.Set("source_image_id", storageImageId)
-
-			if err := d.Set("source_image_reference", FlattenSourceImageReference(profile.ImageReference)); err != nil {
-				return fmt.Errorf("Error setting `source_image_reference`: %+v", err)
-			}
 		}
 
 		// Computed - TODO: implement me
@@ -526,35 +692,233 @@ func resourceLinuxVirtualMachineRead(d *schema.ResourceData, meta interface{}) e
 }
 
 func resourceLinuxVirtualMachineUpdate(d *schema.ResourceData, meta interface{}) error {
-	//client := meta.(*clients.Client).Compute.VMClient
-	//ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
-	//defer cancel()
-
-	//id, err := ParseVirtualMachineID(d.Id())
-	//if err != nil {
-	//	return err
-	//}
-	//
-	//locks.ByName(id.Name, virtualMachineResourceName)
-	//defer locks.UnlockByName(id.Name, virtualMachineResourceName)
-	//
-	//params := compute.VirtualMachineUpdate{}
-	//
-	//shouldShutDown := false
-	//shouldTurnBackOn := true // TODO: unless this was already shut-down, in which case do nothing
-	//
-	//if d.HasChange("network_interface_ids") {
-	//	log.Printf("[DEBUG] Updating the Network Interfaces for Virtual Machine %q (Resource Group %q)..", id.Name, id.ResourceGroup)
-	//	// TODO: do we need to stop the Virtual Machine to make these changes?
-	//	// client.Update(..)
-	//	log.Printf("[DEBUG] Updated the Network Interfaces for Virtual Machine %q (Resource Group %q).", id.Name, id.ResourceGroup)
-	//}
-
-	// setVirtualMachineConnectionInformation(d, read.VirtualMachineProperties)
+	client := meta.(*clients.Client).Compute.VMClient
+	ctx, cancel := timeouts.ForUpdate(meta.(*clients.Client).StopContext, d)
+	defer cancel()
+
+	id, err := ParseVirtualMachineID(d.Id())
+	if err != nil {
+		return err
+	}
+
+	locks.ByName(id.Name, virtualMachineResourceName)
+	defer locks.UnlockByName(id.Name, virtualMachineResourceName)
+
+	log.Printf("[DEBUG] Retrieving Linux Virtual Machine %q (Resource Group %q)..", id.Name, id.ResourceGroup)
+	existing, err := client.Get(ctx, id.ResourceGroup, id.Name, compute.InstanceView)
+	if err != nil {
+		return fmt.Errorf("Error retrieving Linux Virtual Machine %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+	}
+	isRunning := virtualMachineInstanceIsRunning(existing.InstanceView)
+
+	update := compute.VirtualMachineUpdate{
+		VirtualMachineProperties: &compute.VirtualMachineProperties{},
+	}
+
+	// a handful of properties can only be changed while the VM is deallocated - resizing across certain SKU
+	// families being the main one, since the new SKU may not be available on the hardware the VM's currently
+	// running on - so we shut the VM down first and (optionally) turn it back on once the update's applied
+	shouldShutDown := d.HasChange("size") || d.HasChange("network_interface_ids")
+	shouldTurnBackOn := isRunning && d.Get("restart_after_update").(bool)
+
+	if d.HasChange("size") {
+		update.VirtualMachineProperties.HardwareProfile = &compute.HardwareProfile{
+			VMSize: compute.VirtualMachineSizeTypes(d.Get("size").(string)),
+		}
+	}
+
+	if d.HasChange("network_interface_ids") {
+		networkInterfaceIdsRaw := d.Get("network_interface_ids").([]interface{})
+		networkInterfaceIds := expandVirtualMachineNetworkInterfaceIDs(networkInterfaceIdsRaw)
+		update.VirtualMachineProperties.NetworkProfile = &compute.NetworkProfile{
+			NetworkInterfaces: &networkInterfaceIds,
+		}
+	}
+
+	if d.HasChange("os_disk") {
+		osDiskRaw := d.Get("os_disk").([]interface{})
+		osDisk := ExpandVirtualMachineOSDisk(osDiskRaw, compute.Linux)
+		update.VirtualMachineProperties.StorageProfile = &compute.StorageProfile{
+			OsDisk: osDisk,
+		}
+	}
+
+	if d.HasChange("boot_diagnostics") {
+		bootDiagnosticsRaw := d.Get("boot_diagnostics").([]interface{})
+		update.VirtualMachineProperties.DiagnosticsProfile = expandBootDiagnostics(bootDiagnosticsRaw)
+	}
+
+	if d.HasChange("additional_capabilities") {
+		additionalCapabilitiesRaw := d.Get("additional_capabilities").([]interface{})
+		update.VirtualMachineProperties.AdditionalCapabilities = expandVirtualMachineAdditionalCapabilities(additionalCapabilitiesRaw)
+	}
+
+	if d.HasChange("admin_ssh_key") || d.HasChange("secret") {
+		sshKeysRaw := d.Get("admin_ssh_key").(*schema.Set).List()
+		sshKeys := ExpandSSHKeys(sshKeysRaw)
+
+		secretsRaw := d.Get("secret").([]interface{})
+		secrets, err := expandLinuxSecrets(ctx, meta.(*clients.Client).KeyVault.ManagementClient, secretsRaw)
+		if err != nil {
+			return fmt.Errorf("expanding `secret`: %+v", err)
+		}
+
+		update.VirtualMachineProperties.OsProfile = &compute.OSProfile{
+			LinuxConfiguration: &compute.LinuxConfiguration{
+				SSH: &compute.SSHConfiguration{
+					PublicKeys: &sshKeys,
+				},
+			},
+			Secrets: secrets,
+		}
+	}
+
+	if d.HasChange("identity") {
+		identityRaw := d.Get("identity").([]interface{})
+		identity, err := expandVirtualMachineIdentity(identityRaw)
+		if err != nil {
+			return fmt.Errorf("expanding `identity`: %+v", err)
+		}
+		update.Identity = identity
+	}
+
+	if d.HasChange("max_bid_price") {
+		update.VirtualMachineProperties.BillingProfile = &compute.BillingProfile{
+			MaxPrice: utils.Float(d.Get("max_bid_price").(float64)),
+		}
+	}
+
+	if d.HasChange("tags") {
+		update.Tags = tags.Expand(d.Get("tags").(map[string]interface{}))
+	}
+
+	if shouldShutDown {
+		log.Printf("[DEBUG] Deallocating Linux Virtual Machine %q (Resource Group %q)..", id.Name, id.ResourceGroup)
+		deallocateFuture, err := client.Deallocate(ctx, id.ResourceGroup, id.Name)
+		if err != nil {
+			return fmt.Errorf("Error deallocating Linux Virtual Machine %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+		}
+		if err := deallocateFuture.WaitForCompletionRef(ctx, client.Client); err != nil {
+			return fmt.Errorf("Error waiting for deallocation of Linux Virtual Machine %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+		}
+		log.Printf("[DEBUG] Deallocated Linux Virtual Machine %q (Resource Group %q).", id.Name, id.ResourceGroup)
+	}
+
+	log.Printf("[DEBUG] Updating Linux Virtual Machine %q (Resource Group %q)..", id.Name, id.ResourceGroup)
+	updateFuture, err := client.Update(ctx, id.ResourceGroup, id.Name, update)
+	if err != nil {
+		return fmt.Errorf("Error updating Linux Virtual Machine %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+	}
+	if err := updateFuture.WaitForCompletionRef(ctx, client.Client); err != nil {
+		return fmt.Errorf("Error waiting for update of Linux Virtual Machine %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+	}
+	log.Printf("[DEBUG] Updated Linux Virtual Machine %q (Resource Group %q).", id.Name, id.ResourceGroup)
+
+	if shouldShutDown && shouldTurnBackOn {
+		log.Printf("[DEBUG] Starting Linux Virtual Machine %q (Resource Group %q)..", id.Name, id.ResourceGroup)
+		startFuture, err := client.Start(ctx, id.ResourceGroup, id.Name)
+		if err != nil {
+			return fmt.Errorf("Error starting Linux Virtual Machine %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+		}
+		if err := startFuture.WaitForCompletionRef(ctx, client.Client); err != nil {
+			return fmt.Errorf("Error waiting for start of Linux Virtual Machine %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+		}
+		log.Printf("[DEBUG] Started Linux Virtual Machine %q (Resource Group %q).", id.Name, id.ResourceGroup)
+	}
+
+	read, err := client.Get(ctx, id.ResourceGroup, id.Name, "")
+	if err != nil {
+		return fmt.Errorf("Error retrieving Linux Virtual Machine %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+	}
+	setVirtualMachineConnectionInformation(d, read.VirtualMachineProperties)
 
 	return resourceLinuxVirtualMachineRead(d, meta)
 }
 
+// validateSpotVirtualMachineConfiguration enforces the cross-field rules around `priority`/`eviction_policy`/
+// `max_bid_price`/`availability_set_id` - `eviction_policy` and `max_bid_price` only make sense for a Spot
+// Virtual Machine, and a Spot Virtual Machine can't be placed into an `availability_set`. It returns whether
+// `priority` is `Spot`, for the caller to use when populating the Spot-specific API fields.
+func validateSpotVirtualMachineConfiguration(priority string, evictionPolicy string, maxBidPrice float64, hasAvailabilitySet bool) (bool, error) {
+	isSpot := priority == string(compute.Spot)
+
+	if !isSpot && evictionPolicy != "" {
+		return false, fmt.Errorf("An `eviction_policy` can only be specified when `priority` is set to `Spot`")
+	}
+	if !isSpot && maxBidPrice != -1 {
+		return false, fmt.Errorf("A `max_bid_price` can only be specified when `priority` is set to `Spot`")
+	}
+
+	if isSpot && hasAvailabilitySet {
+		return false, fmt.Errorf("A Spot Virtual Machine cannot be placed in an `availability_set`")
+	}
+
+	return isSpot, nil
+}
+
+func expandPlan(input []interface{}) *compute.Plan {
+	if len(input) == 0 {
+		return nil
+	}
+
+	raw := input[0].(map[string]interface{})
+	return &compute.Plan{
+		Name:      utils.String(raw["name"].(string)),
+		Publisher: utils.String(raw["publisher"].(string)),
+		Product:   utils.String(raw["product"].(string)),
+	}
+}
+
+func flattenPlan(input *compute.Plan) []interface{} {
+	if input == nil {
+		return []interface{}{}
+	}
+
+	name := ""
+	if input.Name != nil {
+		name = *input.Name
+	}
+
+	publisher := ""
+	if input.Publisher != nil {
+		publisher = *input.Publisher
+	}
+
+	product := ""
+	if input.Product != nil {
+		product = *input.Product
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"name":      name,
+			"publisher": publisher,
+			"product":   product,
+		},
+	}
+}
+
+// virtualMachineInstanceIsRunning inspects the `PowerState/*` code returned in the Instance View to
+// determine whether the Virtual Machine was running prior to an Update - used to decide whether it should
+// be turned back on afterwards.
+func virtualMachineInstanceIsRunning(instanceView *compute.VirtualMachineInstanceView) bool {
+	if instanceView == nil || instanceView.Statuses == nil {
+		return false
+	}
+
+	for _, status := range *instanceView.Statuses {
+		if status.Code == nil {
+			continue
+		}
+
+		if *status.Code == "PowerState/running" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func resourceLinuxVirtualMachineDelete(d *schema.ResourceData, meta interface{}) error {
 	client := meta.(*clients.Client).Compute.VMClient
 	ctx, cancel := timeouts.ForRead(meta.(*clients.Client).StopContext, d)
@@ -594,6 +958,26 @@ func resourceLinuxVirtualMachineDelete(d *schema.ResourceData, meta interface{})
 	}
 	log.Printf("[DEBUG] Powered Off Linux Virtual Machine %q (Resource Group %q).", id.Name, id.ResourceGroup)
 
+	// capture these before the Delete call, since `existing` won't be refreshed afterwards
+	var osDiskId string
+	if props := existing.VirtualMachineProperties; props != nil {
+		if profile := props.StorageProfile; profile != nil {
+			if disk := profile.OsDisk; disk != nil && disk.ManagedDisk != nil && disk.ManagedDisk.ID != nil {
+				osDiskId = *disk.ManagedDisk.ID
+			}
+		}
+	}
+	networkInterfaceIds := make([]string, 0)
+	if props := existing.VirtualMachineProperties; props != nil {
+		if profile := props.NetworkProfile; profile != nil && profile.NetworkInterfaces != nil {
+			for _, nic := range *profile.NetworkInterfaces {
+				if nic.ID != nil {
+					networkInterfaceIds = append(networkInterfaceIds, *nic.ID)
+				}
+			}
+		}
+	}
+
 	log.Printf("[DEBUG] Deleting Linux Virtual Machine %q (Resource Group %q)..", id.Name, id.ResourceGroup)
 	deleteFuture, err := client.Delete(ctx, id.ResourceGroup, id.Name)
 	if err != nil {
@@ -604,5 +988,58 @@ func resourceLinuxVirtualMachineDelete(d *schema.ResourceData, meta interface{})
 	}
 	log.Printf("[DEBUG] Deleted Linux Virtual Machine %q (Resource Group %q).", id.Name, id.ResourceGroup)
 
+	// best-effort: the VM's gone either way at this point, so any failures here are surfaced as warnings
+	// rather than failing the Delete (matching the historical `azurerm_virtual_machine` behaviour)
+	if d.Get("delete_os_disk_on_deletion").(bool) && osDiskId != "" {
+		deleteManagedDisk(ctx, meta.(*clients.Client), osDiskId)
+	}
+
+	if d.Get("delete_network_interfaces_on_deletion").(bool) {
+		for _, nicId := range networkInterfaceIds {
+			deleteNetworkInterface(ctx, meta.(*clients.Client), nicId)
+		}
+	}
+
 	return nil
 }
+
+func deleteManagedDisk(ctx context.Context, client *clients.Client, diskId string) {
+	id, err := ParseManagedDiskID(diskId)
+	if err != nil {
+		log.Printf("[WARN] Unable to parse OS Disk ID %q - skipping cleanup: %+v", diskId, err)
+		return
+	}
+
+	log.Printf("[DEBUG] Deleting OS Disk %q (Resource Group %q)..", id.Name, id.ResourceGroup)
+	future, err := client.Compute.DisksClient.Delete(ctx, id.ResourceGroup, id.Name)
+	if err != nil {
+		log.Printf("[WARN] Error deleting OS Disk %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+		return
+	}
+	if err := future.WaitForCompletionRef(ctx, client.Compute.DisksClient.Client); err != nil {
+		log.Printf("[WARN] Error waiting for deletion of OS Disk %q (Resource Group %q): %+v", id.Name, id.ResourceGroup, err)
+		return
+	}
+	log.Printf("[DEBUG] Deleted OS Disk %q (Resource Group %q).", id.Name, id.ResourceGroup)
+}
+
+func deleteNetworkInterface(ctx context.Context, client *clients.Client, nicId string) {
+	id, err := azure.ParseAzureResourceID(nicId)
+	if err != nil {
+		log.Printf("[WARN] Unable to parse Network Interface ID %q - skipping cleanup: %+v", nicId, err)
+		return
+	}
+	name := id.Path["networkInterfaces"]
+
+	log.Printf("[DEBUG] Deleting Network Interface %q (Resource Group %q)..", name, id.ResourceGroup)
+	future, err := client.Network.InterfacesClient.Delete(ctx, id.ResourceGroup, name)
+	if err != nil {
+		log.Printf("[WARN] Error deleting Network Interface %q (Resource Group %q): %+v", name, id.ResourceGroup, err)
+		return
+	}
+	if err := future.WaitForCompletionRef(ctx, client.Network.InterfacesClient.Client); err != nil {
+		log.Printf("[WARN] Error waiting for deletion of Network Interface %q (Resource Group %q): %+v", name, id.ResourceGroup, err)
+		return
+	}
+	log.Printf("[DEBUG] Deleted Network Interface %q (Resource Group %q).", name, id.ResourceGroup)
+}
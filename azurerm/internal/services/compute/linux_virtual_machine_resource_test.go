@@ -0,0 +1,45 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+)
+
+func TestValidateSpotVirtualMachineConfiguration_regularPriority(t *testing.T) {
+	isSpot, err := validateSpotVirtualMachineConfiguration(string(compute.Regular), "", -1, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if isSpot {
+		t.Fatalf("expected isSpot to be false for `Regular` priority")
+	}
+}
+
+func TestValidateSpotVirtualMachineConfiguration_spotPriority(t *testing.T) {
+	isSpot, err := validateSpotVirtualMachineConfiguration(string(compute.Spot), string(compute.Deallocate), 0.5, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+	if !isSpot {
+		t.Fatalf("expected isSpot to be true for `Spot` priority")
+	}
+}
+
+func TestValidateSpotVirtualMachineConfiguration_evictionPolicyWithoutSpot(t *testing.T) {
+	if _, err := validateSpotVirtualMachineConfiguration(string(compute.Regular), string(compute.Deallocate), -1, false); err == nil {
+		t.Fatalf("expected an error specifying `eviction_policy` without `priority = Spot` but didn't get one")
+	}
+}
+
+func TestValidateSpotVirtualMachineConfiguration_maxBidPriceWithoutSpot(t *testing.T) {
+	if _, err := validateSpotVirtualMachineConfiguration(string(compute.Regular), "", 0.5, false); err == nil {
+		t.Fatalf("expected an error specifying `max_bid_price` without `priority = Spot` but didn't get one")
+	}
+}
+
+func TestValidateSpotVirtualMachineConfiguration_spotWithAvailabilitySet(t *testing.T) {
+	if _, err := validateSpotVirtualMachineConfiguration(string(compute.Spot), "", -1, true); err == nil {
+		t.Fatalf("expected an error placing a Spot Virtual Machine into an `availability_set` but didn't get one")
+	}
+}
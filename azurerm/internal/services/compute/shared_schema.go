@@ -1,11 +1,20 @@
 package compute
 
 import (
+	"context"
 	"fmt"
+	"log"
+	"regexp"
+	"strings"
 
 	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/v7.1/keyvault"
 	"github.com/hashicorp/terraform-plugin-sdk/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/helper/validation"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/azure"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/helpers/validate"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/clients"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/features"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
 )
 
@@ -32,9 +41,18 @@ func linuxSecretSchema() *schema.Schema {
 						Schema: map[string]*schema.Schema{
 							"url": {
 								Type:         schema.TypeString,
-								Required:     true,
+								Optional:     true,
 								ValidateFunc: azure.ValidateKeyVaultChildId,
 							},
+
+							// a versionless url (e.g. without the trailing `/<version>`) is resolved to the latest
+							// version of the certificate in Key Vault each time Terraform runs, so a rotated
+							// certificate is picked up on the next run without the configured url changing
+							"versionless_url": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validate.NoEmptyStrings,
+							},
 						},
 					},
 				},
@@ -43,7 +61,7 @@ func linuxSecretSchema() *schema.Schema {
 	}
 }
 
-func expandLinuxSecrets(input []interface{}) *[]compute.VaultSecretGroup {
+func expandLinuxSecrets(ctx context.Context, keyVaultClient *keyvault.BaseClient, input []interface{}) (*[]compute.VaultSecretGroup, error) {
 	output := make([]compute.VaultSecretGroup, 0)
 
 	for _, raw := range input {
@@ -56,8 +74,14 @@ func expandLinuxSecrets(input []interface{}) *[]compute.VaultSecretGroup {
 			certificateV := certificateRaw.(map[string]interface{})
 
 			url := certificateV["url"].(string)
+			versionlessUrl := certificateV["versionless_url"].(string)
+			certificateUrl, err := resolveCertificateUrl(ctx, keyVaultClient, url, versionlessUrl)
+			if err != nil {
+				return nil, err
+			}
+
 			certificates = append(certificates, compute.VaultCertificate{
-				CertificateURL: utils.String(url),
+				CertificateURL: utils.String(certificateUrl),
 			})
 		}
 
@@ -69,10 +93,45 @@ func expandLinuxSecrets(input []interface{}) *[]compute.VaultSecretGroup {
 		})
 	}
 
-	return &output
+	return &output, nil
+}
+
+// resolveCertificateUrl converts the configured form (versioned or versionless) of a Key Vault certificate
+// secret URL into the concrete, versioned URL the compute SDK requires - exactly one of `url`/`versionless_url`
+// must be set, since they're mutually exclusive ways of referencing the same certificate. When `versionless_url`
+// is configured this resolves the latest version from Key Vault, so that a rotated certificate is picked up
+// the next time Terraform runs without the URL in config ever needing to change.
+func resolveCertificateUrl(ctx context.Context, keyVaultClient *keyvault.BaseClient, url string, versionlessUrl string) (string, error) {
+	if url == "" && versionlessUrl == "" {
+		return "", fmt.Errorf("Either `url` or `versionless_url` must be specified for each `certificate` block")
+	}
+
+	if url != "" && versionlessUrl != "" {
+		return "", fmt.Errorf("Only one of `url` or `versionless_url` can be specified for each `certificate` block")
+	}
+
+	if url != "" {
+		return url, nil
+	}
+
+	id, err := azure.ParseKeyVaultChildID(versionlessUrl)
+	if err != nil {
+		return "", fmt.Errorf("parsing `versionless_url` %q: %+v", versionlessUrl, err)
+	}
+
+	secret, err := keyVaultClient.GetSecret(ctx, id.KeyVaultBaseUrl, id.Name, "")
+	if err != nil {
+		return "", fmt.Errorf("resolving latest version of certificate %q in %q: %+v", id.Name, id.KeyVaultBaseUrl, err)
+	}
+
+	if secret.ID == nil {
+		return "", fmt.Errorf("resolving latest version of certificate %q in %q: `id` was nil", id.Name, id.KeyVaultBaseUrl)
+	}
+
+	return *secret.ID, nil
 }
 
-func flattenLinuxSecrets(input *[]compute.VaultSecretGroup) []interface{} {
+func flattenLinuxSecrets(input *[]compute.VaultSecretGroup, configured []interface{}) []interface{} {
 	if input == nil {
 		return []interface{}{}
 	}
@@ -85,6 +144,8 @@ func flattenLinuxSecrets(input *[]compute.VaultSecretGroup) []interface{} {
 			keyVaultId = *v.SourceVault.ID
 		}
 
+		configuredCertificates := configuredSecretCertificates(configured, keyVaultId)
+
 		certificates := make([]interface{}, 0)
 
 		if v.VaultCertificates != nil {
@@ -93,8 +154,11 @@ func flattenLinuxSecrets(input *[]compute.VaultSecretGroup) []interface{} {
 					continue
 				}
 
+				url, versionlessUrl := certificateUrlForm(*c.CertificateURL, configuredCertificates)
+
 				certificates = append(certificates, map[string]interface{}{
-					"url": *c.CertificateURL,
+					"url":             url,
+					"versionless_url": versionlessUrl,
 				})
 			}
 		}
@@ -108,6 +172,53 @@ func flattenLinuxSecrets(input *[]compute.VaultSecretGroup) []interface{} {
 	return output
 }
 
+// configuredSecretCertificates returns the `certificate` blocks the user configured under the given
+// `key_vault_id` in either `linuxSecretSchema` or `windowsSecretSchema`, so the flattener can tell whether a
+// resolved certificate URL was originally configured as `url` or `versionless_url` - the compute API only
+// ever returns a concrete, versioned URL, so that distinction can't be recovered from the response alone.
+func configuredSecretCertificates(configured []interface{}, keyVaultId string) []interface{} {
+	for _, raw := range configured {
+		v, ok := raw.(map[string]interface{})
+		if !ok || v["key_vault_id"].(string) != keyVaultId {
+			continue
+		}
+
+		if certificates, ok := v["certificate"].(*schema.Set); ok {
+			return certificates.List()
+		}
+	}
+
+	return nil
+}
+
+// certificateUrlForm preserves whichever of `url`/`versionless_url` the user originally configured for a
+// certificate - falling back to treating the resolved URL as a plain `url` if no matching configuration is
+// found (e.g. on import).
+func certificateUrlForm(resolvedUrl string, configuredCertificates []interface{}) (url string, versionlessUrl string) {
+	for _, raw := range configuredCertificates {
+		v, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		if configured, ok := v["versionless_url"].(string); ok && configured != "" && isResolvedFromVersionlessID(resolvedUrl, configured) {
+			return "", configured
+		}
+
+		if configured, ok := v["url"].(string); ok && configured == resolvedUrl {
+			return configured, ""
+		}
+	}
+
+	return resolvedUrl, ""
+}
+
+// isResolvedFromVersionlessID reports whether resolvedUrl is the concrete, versioned form of the given
+// versionless Key Vault child ID (i.e. the same vault and secret/certificate name, plus a trailing version).
+func isResolvedFromVersionlessID(resolvedUrl string, versionlessID string) bool {
+	return strings.HasPrefix(resolvedUrl, strings.TrimSuffix(versionlessID, "/")+"/")
+}
+
 func SourceImageReferenceSchema() *schema.Schema {
 	// whilst originally I was hoping we could use the 'id' from `azurerm_platform_image' unfortunately Azure doesn't
 	// like this as a value for the 'id' field:
@@ -117,39 +228,114 @@ func SourceImageReferenceSchema() *schema.Schema {
 		Type:          schema.TypeList,
 		Optional:      true,
 		MaxItems:      1,
-		ConflictsWith: []string{"source_image_id"},
+		ConflictsWith: []string{"source_image_id", "shared_image"},
 		Elem: &schema.Resource{
 			Schema: map[string]*schema.Schema{
+				// the image a VM was created from can't be changed after the fact - a genuine change to
+				// any of these (as opposed to `sourceImageReferenceCustomizeDiff` resolving `latest` to a
+				// concrete version at creation time) requires replacing the VM
 				"publisher": {
 					Type:     schema.TypeString,
 					Required: true,
+					ForceNew: true,
 				},
 				"offer": {
 					Type:     schema.TypeString,
 					Required: true,
+					ForceNew: true,
 				},
 				"sku": {
 					Type:     schema.TypeString,
 					Required: true,
+					ForceNew: true,
 				},
 				"version": {
 					Type:     schema.TypeString,
 					Required: true,
+					ForceNew: true,
 				},
 			},
 		},
 	}
 }
 
-func ExpandSourceImageReference(referenceInput []interface{}, imageId string) (*compute.ImageReference, error) {
+// sharedImageGalleryResourceGroupNameSchema is `azure.SchemaResourceGroupNameOptional` with `ForceNew` set,
+// since (like every other field in `SharedImageReferenceSchema`) it can't be changed without replacing the VM.
+func sharedImageGalleryResourceGroupNameSchema() *schema.Schema {
+	s := azure.SchemaResourceGroupNameOptional()
+	s.ForceNew = true
+	return s
+}
+
+// SharedImageReferenceSchema is the schema for referencing an image from a Shared Image Gallery or a
+// Community Gallery - this is kept as a separate block from `source_image_reference` since the latter
+// is a per-publisher reference, whilst this is a reference to a specific (Community) Gallery Image Version.
+func SharedImageReferenceSchema() *schema.Schema {
+	return &schema.Schema{
+		Type:          schema.TypeList,
+		Optional:      true,
+		MaxItems:      1,
+		ConflictsWith: []string{"source_image_id", "source_image_reference"},
+		Elem: &schema.Resource{
+			// as with `source_image_reference`, the image a VM was created from can't be changed after the
+			// fact - every field here requires replacing the VM
+			Schema: map[string]*schema.Schema{
+				"gallery_name": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ForceNew:     true,
+					ValidateFunc: validate.NoEmptyStrings,
+				},
+
+				"image_name": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ForceNew:     true,
+					ValidateFunc: validate.NoEmptyStrings,
+				},
+
+				"version": {
+					Type:         schema.TypeString,
+					Required:     true,
+					ForceNew:     true,
+					ValidateFunc: validate.NoEmptyStrings,
+				},
+
+				// Optional - required for a Shared Image Gallery reference, not used for a Community Gallery one
+				"resource_group_name": sharedImageGalleryResourceGroupNameSchema(),
+
+				"subscription_id": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ForceNew:     true,
+					ValidateFunc: validate.NoEmptyStrings,
+				},
+
+				// Optional - used instead of gallery_name/resource_group_name/subscription_id for a Community Gallery
+				"community_gallery_id": {
+					Type:         schema.TypeString,
+					Optional:     true,
+					ForceNew:     true,
+					ValidateFunc: validate.NoEmptyStrings,
+				},
+			},
+		},
+	}
+}
+
+func ExpandSourceImageReference(referenceInput []interface{}, sharedImageInput []interface{}, imageId string) (*compute.ImageReference, error) {
 	if imageId != "" {
 		return &compute.ImageReference{
 			ID: utils.String(imageId),
 		}, nil
 	}
 
+	if len(sharedImageInput) > 0 {
+		return expandSharedImageReference(sharedImageInput)
+	}
+
 	if len(referenceInput) == 0 {
-		return nil, fmt.Errorf("Either a `source_image_id` or a `source_image_reference` block must be specified!")
+		return nil, fmt.Errorf("Either a `source_image_id`, `source_image_reference` or `shared_image` block must be specified!")
 	}
 
 	raw := referenceInput[0].(map[string]interface{})
@@ -161,8 +347,35 @@ func ExpandSourceImageReference(referenceInput []interface{}, imageId string) (*
 	}, nil
 }
 
+func expandSharedImageReference(input []interface{}) (*compute.ImageReference, error) {
+	raw := input[0].(map[string]interface{})
+
+	imageName := raw["image_name"].(string)
+	version := raw["version"].(string)
+
+	if communityGalleryId := raw["community_gallery_id"].(string); communityGalleryId != "" {
+		id := fmt.Sprintf("/CommunityGalleries/%s/Images/%s/Versions/%s", communityGalleryId, imageName, version)
+		return &compute.ImageReference{
+			ID: utils.String(id),
+		}, nil
+	}
+
+	galleryName := raw["gallery_name"].(string)
+	resourceGroup := raw["resource_group_name"].(string)
+	subscriptionId := raw["subscription_id"].(string)
+	if galleryName == "" || resourceGroup == "" || subscriptionId == "" {
+		return nil, fmt.Errorf("`gallery_name`, `resource_group_name` and `subscription_id` must be specified when `community_gallery_id` isn't set")
+	}
+
+	id := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s/providers/Microsoft.Compute/galleries/%s/images/%s/versions/%s", subscriptionId, resourceGroup, galleryName, imageName, version)
+	return &compute.ImageReference{
+		ID: utils.String(id),
+	}, nil
+}
+
 func FlattenSourceImageReference(input *compute.ImageReference) []interface{} {
-	// since the image id is pulled out as a separate field, if that's set we should return an empty block here
+	// since the image id, and the shared/community gallery image are pulled out as separate fields, if either of
+	// those are set we should return an empty block here
 	if input == nil || input.ID != nil {
 		return []interface{}{}
 	}
@@ -192,6 +405,47 @@ func FlattenSourceImageReference(input *compute.ImageReference) []interface{} {
 	}
 }
 
+var sharedImageGalleryIdRegex = regexp.MustCompile(`^/subscriptions/([^/]+)/resourceGroups/([^/]+)/providers/Microsoft\.Compute/galleries/([^/]+)/images/([^/]+)/versions/([^/]+)$`)
+var communityGalleryIdRegex = regexp.MustCompile(`^/CommunityGalleries/([^/]+)/Images/([^/]+)/Versions/([^/]+)$`)
+
+// FlattenSharedImageReference detects whether the given Image Reference ID is in the form of a Shared Image
+// Gallery or Community Gallery Image Version, and if so populates the `shared_image` block from it.
+func FlattenSharedImageReference(input *compute.ImageReference) []interface{} {
+	if input == nil || input.ID == nil {
+		return []interface{}{}
+	}
+
+	id := *input.ID
+
+	if match := communityGalleryIdRegex.FindStringSubmatch(id); match != nil {
+		return []interface{}{
+			map[string]interface{}{
+				"community_gallery_id": match[1],
+				"image_name":           match[2],
+				"version":              match[3],
+				"gallery_name":         "",
+				"resource_group_name":  "",
+				"subscription_id":      "",
+			},
+		}
+	}
+
+	if match := sharedImageGalleryIdRegex.FindStringSubmatch(id); match != nil {
+		return []interface{}{
+			map[string]interface{}{
+				"subscription_id":      match[1],
+				"resource_group_name":  match[2],
+				"gallery_name":         match[3],
+				"image_name":           match[4],
+				"version":              match[5],
+				"community_gallery_id": "",
+			},
+		}
+	}
+
+	return []interface{}{}
+}
+
 func windowsSecretSchema() *schema.Schema {
 	return &schema.Schema{
 		Type:     schema.TypeList,
@@ -212,14 +466,32 @@ func windowsSecretSchema() *schema.Schema {
 					Elem: &schema.Resource{
 						Schema: map[string]*schema.Schema{
 							"store": {
-								Type:     schema.TypeString,
-								Required: true,
+								Type:         schema.TypeString,
+								Required:     true,
+								ValidateFunc: validate.NoEmptyStrings,
 							},
 							"url": {
 								Type:         schema.TypeString,
-								Required:     true,
+								Optional:     true,
 								ValidateFunc: azure.ValidateKeyVaultChildId,
 							},
+
+							// a versionless url (e.g. without the trailing `/<version>`) is resolved to the latest
+							// version of the certificate in Key Vault each time Terraform runs, so a rotated
+							// certificate is picked up on the next run without the configured url changing
+							"versionless_url": {
+								Type:         schema.TypeString,
+								Optional:     true,
+								ValidateFunc: validate.NoEmptyStrings,
+							},
+
+							// `store` is otherwise restricted to `windowsCertificateStoreNames` - set this to
+							// target a custom store created by an extension that isn't one of the documented ones
+							"allow_custom_store": {
+								Type:     schema.TypeBool,
+								Optional: true,
+								Default:  false,
+							},
 						},
 					},
 				},
@@ -228,7 +500,30 @@ func windowsSecretSchema() *schema.Schema {
 	}
 }
 
-func expandWindowsSecrets(input []interface{}) *[]compute.VaultSecretGroup {
+// windowsCertificateStoreNames are the Windows certificate store names documented by the VM Agent/the
+// Azure Windows Secrets extension - any other value is rejected by `expandWindowsSecrets` unless the
+// certificate's `allow_custom_store` is set, since it's far more likely to be a typo than an intentional
+// choice.
+var windowsCertificateStoreNames = []string{
+	"My",
+	"Root",
+	"CA",
+	"TrustedPublisher",
+	"AuthRoot",
+	"TrustedPeople",
+	"Disallowed",
+	"AddressBook",
+	"SmartCardRoot",
+	"Remote",
+	"TrustedDevices",
+}
+
+// validateWindowsCertificateStore is a `validation.StringInSlice`-style validator for the documented
+// Windows certificate store names. It's applied from `expandWindowsSecrets` (rather than as a schema
+// `ValidateFunc`) since whether it's enforced depends on the sibling `allow_custom_store` flag.
+var validateWindowsCertificateStore = validation.StringInSlice(windowsCertificateStoreNames, false)
+
+func expandWindowsSecrets(ctx context.Context, keyVaultClient *keyvault.BaseClient, input []interface{}) (*[]compute.VaultSecretGroup, error) {
 	output := make([]compute.VaultSecretGroup, 0)
 
 	for _, raw := range input {
@@ -237,14 +532,31 @@ func expandWindowsSecrets(input []interface{}) *[]compute.VaultSecretGroup {
 		keyVaultId := v["key_vault_id"].(string)
 		certificatesRaw := v["certificate"].(*schema.Set).List()
 		certificates := make([]compute.VaultCertificate, 0)
-		for _, certificateRaw := range certificatesRaw {
+		for certificateIndex, certificateRaw := range certificatesRaw {
 			certificateV := certificateRaw.(map[string]interface{})
 
 			store := certificateV["store"].(string)
+			if store == "" {
+				return nil, fmt.Errorf("`store` must be specified for certificate %d under `key_vault_id` %q", certificateIndex, keyVaultId)
+			}
+
+			allowCustomStore := certificateV["allow_custom_store"].(bool)
+			if !allowCustomStore {
+				if _, errs := validateWindowsCertificateStore(store, "store"); len(errs) > 0 {
+					return nil, fmt.Errorf("certificate %d under `key_vault_id` %q: %+v (set `allow_custom_store` to target a custom certificate store)", certificateIndex, keyVaultId, errs[0])
+				}
+			}
+
 			url := certificateV["url"].(string)
+			versionlessUrl := certificateV["versionless_url"].(string)
+			certificateUrl, err := resolveCertificateUrl(ctx, keyVaultClient, url, versionlessUrl)
+			if err != nil {
+				return nil, err
+			}
+
 			certificates = append(certificates, compute.VaultCertificate{
 				CertificateStore: utils.String(store),
-				CertificateURL:   utils.String(url),
+				CertificateURL:   utils.String(certificateUrl),
 			})
 		}
 
@@ -256,10 +568,10 @@ func expandWindowsSecrets(input []interface{}) *[]compute.VaultSecretGroup {
 		})
 	}
 
-	return &output
+	return &output, nil
 }
 
-func flattenWindowsSecrets(input *[]compute.VaultSecretGroup) []interface{} {
+func flattenWindowsSecrets(input *[]compute.VaultSecretGroup, configured []interface{}) []interface{} {
 	if input == nil {
 		return []interface{}{}
 	}
@@ -272,6 +584,8 @@ func flattenWindowsSecrets(input *[]compute.VaultSecretGroup) []interface{} {
 			keyVaultId = *v.SourceVault.ID
 		}
 
+		configuredCertificates := configuredSecretCertificates(configured, keyVaultId)
+
 		certificates := make([]interface{}, 0)
 
 		if v.VaultCertificates != nil {
@@ -281,14 +595,15 @@ func flattenWindowsSecrets(input *[]compute.VaultSecretGroup) []interface{} {
 					store = *c.CertificateStore
 				}
 
-				url := ""
+				url, versionlessUrl := "", ""
 				if c.CertificateURL != nil {
-					url = *c.CertificateURL
+					url, versionlessUrl = certificateUrlForm(*c.CertificateURL, configuredCertificates)
 				}
 
 				certificates = append(certificates, map[string]interface{}{
-					"store": store,
-					"url":   url,
+					"store":           store,
+					"url":             url,
+					"versionless_url": versionlessUrl,
 				})
 			}
 		}
@@ -301,3 +616,343 @@ func flattenWindowsSecrets(input *[]compute.VaultSecretGroup) []interface{} {
 
 	return output
 }
+
+// secretsCustomizeDiff is installed as a CustomizeDiff on resources embedding `linuxSecretSchema`/
+// `windowsSecretSchema` - for every certificate configured via `versionless_url` it resolves the latest
+// version from Key Vault and compares that against what's actually installed on the VM, marking `secret`
+// as changed (producing an in-place Update) when rotation is detected.
+func secretsCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if diff.Id() == "" {
+		// nothing's installed yet on a brand new resource - the initial resolution happens in
+		// `expandLinuxSecrets`/`expandWindowsSecrets` at Create time
+		return nil
+	}
+
+	secretsRaw := diff.Get("secret").([]interface{})
+	versionlessUrls := versionlessCertificateURLs(secretsRaw)
+	if len(versionlessUrls) == 0 {
+		return nil
+	}
+
+	ctx := meta.(*clients.Client).StopContext
+	vmClient := meta.(*clients.Client).Compute.VMClient
+	keyVaultClient := meta.(*clients.Client).KeyVault.ManagementClient
+
+	id, err := ParseVirtualMachineID(diff.Id())
+	if err != nil {
+		return err
+	}
+
+	existing, err := vmClient.Get(ctx, id.ResourceGroup, id.Name, "")
+	if err != nil {
+		// the VM/Key Vault may not be reachable from wherever `plan` is being run - don't block the plan,
+		// this is retried (and surfaced properly) during Apply
+		log.Printf("[WARN] Unable to check installed certificates on Virtual Machine %q (Resource Group %q) for rotation: %+v", id.Name, id.ResourceGroup, err)
+		return nil
+	}
+
+	installed := installedCertificateURLs(existing.VirtualMachineProperties)
+
+	for _, versionlessUrl := range versionlessUrls {
+		resolved, err := resolveCertificateUrl(ctx, keyVaultClient, "", versionlessUrl)
+		if err != nil {
+			log.Printf("[WARN] Unable to resolve latest version of certificate %q: %+v", versionlessUrl, err)
+			continue
+		}
+
+		if !installed[resolved] {
+			if err := diff.SetNewComputed("secret"); err != nil {
+				return fmt.Errorf("marking `secret` as changed after detecting certificate rotation: %+v", err)
+			}
+			return nil
+		}
+	}
+
+	return nil
+}
+
+// versionlessCertificateURLs returns every `versionless_url` configured across all `secret`/`certificate`
+// blocks, so `secretsCustomizeDiff` can skip the VM/Key Vault lookups entirely when none are in use.
+func versionlessCertificateURLs(secretsRaw []interface{}) []string {
+	urls := make([]string, 0)
+
+	for _, raw := range secretsRaw {
+		v, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		certificatesRaw, ok := v["certificate"].(*schema.Set)
+		if !ok {
+			continue
+		}
+
+		for _, certRaw := range certificatesRaw.List() {
+			cert, ok := certRaw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if versionlessUrl := cert["versionless_url"].(string); versionlessUrl != "" {
+				urls = append(urls, versionlessUrl)
+			}
+		}
+	}
+
+	return urls
+}
+
+// installedCertificateURLs returns the set of Key Vault certificate secret URLs currently installed on a
+// VM, as reported by the compute API itself - this is the ground truth `secretsCustomizeDiff` compares the
+// latest Key Vault version against.
+func installedCertificateURLs(props *compute.VirtualMachineProperties) map[string]bool {
+	installed := make(map[string]bool)
+
+	if props == nil || props.OsProfile == nil || props.OsProfile.Secrets == nil {
+		return installed
+	}
+
+	for _, group := range *props.OsProfile.Secrets {
+		if group.VaultCertificates == nil {
+			continue
+		}
+
+		for _, cert := range *group.VaultCertificates {
+			if cert.CertificateURL != nil {
+				installed[*cert.CertificateURL] = true
+			}
+		}
+	}
+
+	return installed
+}
+
+// sourceImageReferenceCustomizeDiff is installed as a CustomizeDiff on every resource which embeds
+// `SourceImageReferenceSchema` - it verifies that the configured publisher/offer/sku/version tuple exists
+// for the target location, warns if the SKU is deprecated, and resolves `version = "latest"` to the
+// concrete version Azure would use at creation time.
+//
+// The resolved version is only ever looked up when the resource is being created or when one of
+// `publisher`/`offer`/`sku`/`version` has actually changed in config - once resolved it's written to state
+// and treated as locked from then on, so a plan/refresh never re-hits the platform images API, and a
+// `latest` pointing at a newer image upstream can never produce a diff that `Update` has no way to apply.
+//
+// This is a no-op when `source_image_id` or `shared_image` is used instead, and can be disabled entirely
+// via `features.SkipImageValidation` for offline/air-gapped usage where the platform images API isn't
+// reachable.
+func sourceImageReferenceCustomizeDiff(diff *schema.ResourceDiff, meta interface{}) error {
+	if features.SkipImageValidation() {
+		return nil
+	}
+
+	if sourceImageId := diff.Get("source_image_id").(string); sourceImageId != "" {
+		return nil
+	}
+
+	referenceRaw := diff.Get("source_image_reference").([]interface{})
+	if len(referenceRaw) == 0 {
+		return nil
+	}
+
+	isNewResource := diff.Id() == ""
+	referenceChanged := diff.HasChange("source_image_reference.0.publisher") ||
+		diff.HasChange("source_image_reference.0.offer") ||
+		diff.HasChange("source_image_reference.0.sku") ||
+		diff.HasChange("source_image_reference.0.version")
+	if !isNewResource && !referenceChanged {
+		return nil
+	}
+
+	reference := referenceRaw[0].(map[string]interface{})
+	publisher := reference["publisher"].(string)
+	offer := reference["offer"].(string)
+	sku := reference["sku"].(string)
+	version := reference["version"].(string)
+	location := azure.NormalizeLocation(diff.Get("location").(string))
+
+	client := meta.(*clients.Client).Compute.VMImageClient
+	ctx := meta.(*clients.Client).StopContext
+
+	resolvedVersion, deprecated, err := resolvePlatformImageVersion(ctx, client, location, publisher, offer, sku, version)
+	if err != nil {
+		// the platform images API can be flaky/unreachable in some environments - surface this as a warning
+		// rather than blocking the plan, since `features.SkipImageValidation` exists for the air-gapped case
+		log.Printf("[WARN] Unable to validate `source_image_reference` (%s:%s:%s:%s): %+v", publisher, offer, sku, version, err)
+		return nil
+	}
+
+	if deprecated {
+		log.Printf("[WARN] The image SKU %q (%s:%s) is marked as deprecated by the publisher", sku, publisher, offer)
+	}
+
+	if version == "latest" && resolvedVersion != "" && resolvedVersion != version {
+		reference["version"] = resolvedVersion
+		if err := diff.SetNew("source_image_reference", []interface{}{reference}); err != nil {
+			return fmt.Errorf("setting resolved `source_image_reference.0.version`: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+// resolvePlatformImageVersion calls the platform images API to confirm the publisher/offer/sku/version
+// tuple exists in the given location, returning the concrete version `latest` resolves to and whether the
+// SKU is flagged as deprecated.
+func resolvePlatformImageVersion(ctx context.Context, client compute.VirtualMachineImagesClient, location, publisher, offer, sku, version string) (string, bool, error) {
+	resolvedVersion := version
+	if version == "latest" {
+		result, err := client.List(ctx, location, publisher, offer, sku, "", utils.Int32(1), "name desc")
+		if err != nil {
+			return "", false, fmt.Errorf("listing versions for %s:%s:%s: %+v", publisher, offer, sku, err)
+		}
+
+		if result.Value == nil || len(*result.Value) == 0 {
+			return "", false, fmt.Errorf("no versions found for %s:%s:%s in %q", publisher, offer, sku, location)
+		}
+
+		latest := (*result.Value)[0]
+		if latest.Name == nil {
+			return "", false, fmt.Errorf("version name was nil for %s:%s:%s in %q", publisher, offer, sku, location)
+		}
+
+		resolvedVersion = *latest.Name
+	}
+
+	// the `List` response doesn't include the image's properties, and deprecation status isn't modeled
+	// by this API version's generated types at all - it's only exposed via the raw `AdditionalProperties`
+	// the service returns - so a `Get` is always needed to check it, even when the version was already known.
+	image, err := client.Get(ctx, location, publisher, offer, sku, resolvedVersion)
+	if err != nil {
+		return "", false, fmt.Errorf("retrieving %s:%s:%s:%s in %q: %+v", publisher, offer, sku, resolvedVersion, location, err)
+	}
+
+	return resolvedVersion, imageIsDeprecated(image.VirtualMachineImageProperties), nil
+}
+
+// imageIsDeprecated inspects the `imageDeprecationStatus` the VM Images API returns alongside an image's
+// properties. The installed SDK's generated types don't model this field, so it's read out of
+// `AdditionalProperties` instead of off a typed struct field.
+func imageIsDeprecated(props *compute.VirtualMachineImageProperties) bool {
+	if props == nil || props.AdditionalProperties == nil {
+		return false
+	}
+
+	status, ok := props.AdditionalProperties["imageDeprecationStatus"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	state, ok := status["imageState"].(string)
+	if !ok {
+		return false
+	}
+
+	return strings.EqualFold(state, "ScheduledForDeprecation") || strings.EqualFold(state, "Deprecated")
+}
+
+func virtualMachineIdentitySchema() *schema.Schema {
+	return &schema.Schema{
+		Type:     schema.TypeList,
+		Optional: true,
+		MaxItems: 1,
+		Elem: &schema.Resource{
+			Schema: map[string]*schema.Schema{
+				"type": {
+					Type:     schema.TypeString,
+					Required: true,
+					ValidateFunc: validation.StringInSlice([]string{
+						string(compute.ResourceIdentityTypeSystemAssigned),
+						string(compute.ResourceIdentityTypeUserAssigned),
+						string(compute.ResourceIdentityTypeSystemAssignedUserAssigned),
+					}, false),
+				},
+
+				// a Set, rather than a List, since the order User Assigned Identities are attached in doesn't
+				// matter - a List would produce a perpetual diff any time a user's config order didn't match
+				// the order returned by the API
+				"identity_ids": {
+					Type:     schema.TypeSet,
+					Optional: true,
+					Elem: &schema.Schema{
+						Type:         schema.TypeString,
+						ValidateFunc: azure.ValidateResourceID,
+					},
+				},
+
+				// Computed
+				"principal_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+
+				"tenant_id": {
+					Type:     schema.TypeString,
+					Computed: true,
+				},
+			},
+		},
+	}
+}
+
+func expandVirtualMachineIdentity(input []interface{}) (*compute.VirtualMachineIdentity, error) {
+	if len(input) == 0 {
+		return &compute.VirtualMachineIdentity{
+			Type: compute.ResourceIdentityTypeNone,
+		}, nil
+	}
+
+	v := input[0].(map[string]interface{})
+	identityType := compute.ResourceIdentityType(v["type"].(string))
+
+	identityIdsRaw := v["identity_ids"].(*schema.Set).List()
+	identityIds := make(map[string]*compute.VirtualMachineIdentityUserAssignedIdentitiesValue)
+	for _, raw := range identityIdsRaw {
+		identityIds[raw.(string)] = &compute.VirtualMachineIdentityUserAssignedIdentitiesValue{}
+	}
+
+	if identityType == compute.ResourceIdentityTypeUserAssigned || identityType == compute.ResourceIdentityTypeSystemAssignedUserAssigned {
+		if len(identityIds) == 0 {
+			return nil, fmt.Errorf("At least one `identity_ids` entry must be specified when `type` is set to %q", identityType)
+		}
+	}
+
+	identity := compute.VirtualMachineIdentity{
+		Type: identityType,
+	}
+	if len(identityIds) > 0 {
+		identity.UserAssignedIdentities = identityIds
+	}
+
+	return &identity, nil
+}
+
+func flattenVirtualMachineIdentity(input *compute.VirtualMachineIdentity) []interface{} {
+	if input == nil || input.Type == compute.ResourceIdentityTypeNone {
+		return []interface{}{}
+	}
+
+	// `identity_ids` is a Set, so the order these are appended in doesn't affect the resulting diff
+	identityIds := make([]interface{}, 0)
+	for id := range input.UserAssignedIdentities {
+		identityIds = append(identityIds, id)
+	}
+
+	principalId := ""
+	if input.PrincipalID != nil {
+		principalId = *input.PrincipalID
+	}
+
+	tenantId := ""
+	if input.TenantID != nil {
+		tenantId = *input.TenantID
+	}
+
+	return []interface{}{
+		map[string]interface{}{
+			"type":         string(input.Type),
+			"identity_ids": identityIds,
+			"principal_id": principalId,
+			"tenant_id":    tenantId,
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package compute
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/services/compute/mgmt/2019-07-01/compute"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+func TestExpandSourceImageReference_sharedImageGallery(t *testing.T) {
+	sharedImage := []interface{}{
+		map[string]interface{}{
+			"gallery_name":         "myGallery",
+			"image_name":           "myImage",
+			"version":              "1.0.0",
+			"resource_group_name":  "myResourceGroup",
+			"subscription_id":      "00000000-0000-0000-0000-000000000000",
+			"community_gallery_id": "",
+		},
+	}
+
+	result, err := ExpandSourceImageReference(nil, sharedImage, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	expected := "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/myResourceGroup/providers/Microsoft.Compute/galleries/myGallery/images/myImage/versions/1.0.0"
+	if result.ID == nil || *result.ID != expected {
+		t.Fatalf("expected ID %q but got %+v", expected, result.ID)
+	}
+}
+
+func TestExpandSourceImageReference_communityGallery(t *testing.T) {
+	sharedImage := []interface{}{
+		map[string]interface{}{
+			"gallery_name":         "",
+			"image_name":           "myImage",
+			"version":              "1.0.0",
+			"resource_group_name":  "",
+			"subscription_id":      "",
+			"community_gallery_id": "myCommunityGallery",
+		},
+	}
+
+	result, err := ExpandSourceImageReference(nil, sharedImage, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %+v", err)
+	}
+
+	expected := "/CommunityGalleries/myCommunityGallery/Images/myImage/Versions/1.0.0"
+	if result.ID == nil || *result.ID != expected {
+		t.Fatalf("expected ID %q but got %+v", expected, result.ID)
+	}
+}
+
+func TestExpandSourceImageReference_sharedImageMissingRequiredFields(t *testing.T) {
+	sharedImage := []interface{}{
+		map[string]interface{}{
+			"gallery_name":         "",
+			"image_name":           "myImage",
+			"version":              "1.0.0",
+			"resource_group_name":  "",
+			"subscription_id":      "",
+			"community_gallery_id": "",
+		},
+	}
+
+	if _, err := ExpandSourceImageReference(nil, sharedImage, ""); err == nil {
+		t.Fatalf("expected an error but didn't get one")
+	}
+}
+
+func TestFlattenSharedImageReference_sharedImageGallery(t *testing.T) {
+	input := &compute.ImageReference{
+		ID: utils.String("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/myResourceGroup/providers/Microsoft.Compute/galleries/myGallery/images/myImage/versions/1.0.0"),
+	}
+
+	result := FlattenSharedImageReference(input)
+	if len(result) != 1 {
+		t.Fatalf("expected 1 block but got %d", len(result))
+	}
+
+	block := result[0].(map[string]interface{})
+	if block["gallery_name"] != "myGallery" {
+		t.Fatalf("expected `gallery_name` to be %q but got %q", "myGallery", block["gallery_name"])
+	}
+	if block["image_name"] != "myImage" {
+		t.Fatalf("expected `image_name` to be %q but got %q", "myImage", block["image_name"])
+	}
+	if block["community_gallery_id"] != "" {
+		t.Fatalf("expected `community_gallery_id` to be empty but got %q", block["community_gallery_id"])
+	}
+}
+
+func TestFlattenSharedImageReference_communityGallery(t *testing.T) {
+	input := &compute.ImageReference{
+		ID: utils.String("/CommunityGalleries/myCommunityGallery/Images/myImage/Versions/1.0.0"),
+	}
+
+	result := FlattenSharedImageReference(input)
+	if len(result) != 1 {
+		t.Fatalf("expected 1 block but got %d", len(result))
+	}
+
+	block := result[0].(map[string]interface{})
+	if block["community_gallery_id"] != "myCommunityGallery" {
+		t.Fatalf("expected `community_gallery_id` to be %q but got %q", "myCommunityGallery", block["community_gallery_id"])
+	}
+	if block["gallery_name"] != "" {
+		t.Fatalf("expected `gallery_name` to be empty but got %q", block["gallery_name"])
+	}
+}
+
+func TestFlattenSharedImageReference_neitherMatches(t *testing.T) {
+	input := &compute.ImageReference{
+		ID: utils.String("/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/myResourceGroup/providers/Microsoft.Compute/images/myImage"),
+	}
+
+	result := FlattenSharedImageReference(input)
+	if len(result) != 0 {
+		t.Fatalf("expected no blocks but got %+v", result)
+	}
+}
@@ -0,0 +1,21 @@
+package client
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/keyvault/v7.1/keyvault"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/common"
+)
+
+type Client struct {
+	// ManagementClient talks to a Key Vault's own data-plane API (secrets/certificates/keys), as opposed to
+	// the Resource Manager API used to manage the vault resource itself.
+	ManagementClient *keyvault.BaseClient
+}
+
+func NewClient(o *common.ClientOptions) *Client {
+	managementClient := keyvault.New()
+	o.ConfigureClient(&managementClient.Client, o.KeyVaultAuthorizer)
+
+	return &Client{
+		ManagementClient: &managementClient,
+	}
+}
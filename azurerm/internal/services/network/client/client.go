@@ -0,0 +1,19 @@
+package client
+
+import (
+	"github.com/Azure/azure-sdk-for-go/services/network/mgmt/2019-09-01/network"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/internal/common"
+)
+
+type Client struct {
+	InterfacesClient *network.InterfacesClient
+}
+
+func NewClient(o *common.ClientOptions) *Client {
+	interfacesClient := network.NewInterfacesClientWithBaseURI(o.ResourceManagerEndpoint, o.SubscriptionId)
+	o.ConfigureClient(&interfacesClient.Client, o.ResourceManagerAuthorizer)
+
+	return &Client{
+		InterfacesClient: &interfacesClient,
+	}
+}
@@ -2,9 +2,12 @@ package azurerm
 
 import (
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/arm/containerinstance"
+	"github.com/hashicorp/terraform/helper/resource"
 	"github.com/hashicorp/terraform/helper/schema"
 	"github.com/hashicorp/terraform/helper/validation"
 	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
@@ -16,6 +19,11 @@ func resourceArmContainerGroup() *schema.Resource {
 		Read:   resourceArmContainerGroupRead,
 		Delete: resourceArmContainerGroupDelete,
 
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Delete: schema.DefaultTimeout(30 * time.Minute),
+		},
+
 		Schema: map[string]*schema.Schema{
 			"name": {
 				Type:     schema.TypeString,
@@ -39,9 +47,18 @@ func resourceArmContainerGroup() *schema.Resource {
 				DiffSuppressFunc: ignoreCaseDiffSuppressFunc,
 				ValidateFunc: validation.StringInSlice([]string{
 					"Public",
+					"Private",
 				}, true),
 			},
 
+			// required when `ip_address_type` is `Private` - references an `azurerm_network_profile` which
+			// in turn delegates a subnet in an existing VNet to Azure Container Instances
+			"network_profile_id": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
 			"os_type": {
 				Type:             schema.TypeString,
 				Required:         true,
@@ -61,6 +78,59 @@ func resourceArmContainerGroup() *schema.Resource {
 				ForceNew: true,
 			},
 
+			"dns_name_label": {
+				Type:     schema.TypeString,
+				Optional: true,
+				ForceNew: true,
+			},
+
+			"fqdn": {
+				Type:     schema.TypeString,
+				Computed: true,
+				ForceNew: true,
+			},
+
+			"restart_policy": {
+				Type:             schema.TypeString,
+				Optional:         true,
+				Default:          string(containerinstance.Always),
+				ForceNew:         true,
+				DiffSuppressFunc: ignoreCaseDiffSuppressFunc,
+				ValidateFunc: validation.StringInSlice([]string{
+					string(containerinstance.Always),
+					string(containerinstance.Never),
+					string(containerinstance.OnFailure),
+				}, true),
+			},
+
+			"image_registry_credential": {
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"server": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+
+						"username": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+
+						"password": {
+							Type:      schema.TypeString,
+							Required:  true,
+							ForceNew:  true,
+							Sensitive: true,
+						},
+					},
+				},
+			},
+
 			"container": {
 				Type:     schema.TypeList,
 				Required: true,
@@ -91,22 +161,40 @@ func resourceArmContainerGroup() *schema.Resource {
 							ForceNew: true,
 						},
 
-						"port": {
-							Type:         schema.TypeInt,
-							Optional:     true,
-							ForceNew:     true,
-							ValidateFunc: validation.IntBetween(1, 65535),
+						"ports": {
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"port": {
+										Type:         schema.TypeInt,
+										Required:     true,
+										ForceNew:     true,
+										ValidateFunc: validation.IntBetween(1, 65535),
+									},
+
+									"protocol": {
+										Type:             schema.TypeString,
+										Optional:         true,
+										ForceNew:         true,
+										DiffSuppressFunc: ignoreCaseDiffSuppressFunc,
+										ValidateFunc: validation.StringInSlice([]string{
+											"tcp",
+											"udp",
+										}, true),
+									},
+								},
+							},
 						},
 
-						"protocol": {
-							Type:             schema.TypeString,
-							Optional:         true,
-							ForceNew:         true,
-							DiffSuppressFunc: ignoreCaseDiffSuppressFunc,
-							ValidateFunc: validation.StringInSlice([]string{
-								"tcp",
-								"udp",
-							}, true),
+						"commands": {
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Schema{
+								Type: schema.TypeString,
+							},
 						},
 
 						"env_var": {
@@ -129,6 +217,124 @@ func resourceArmContainerGroup() *schema.Resource {
 								},
 							},
 						},
+
+						"volume_mount": {
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"name": {
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+
+									"mount_path": {
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+
+									"read_only": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										ForceNew: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+
+			"volume": {
+				Type:     schema.TypeList,
+				Optional: true,
+				ForceNew: true,
+				Elem: &schema.Resource{
+					Schema: map[string]*schema.Schema{
+						"name": {
+							Type:     schema.TypeString,
+							Required: true,
+							ForceNew: true,
+						},
+
+						"empty_dir": {
+							Type:     schema.TypeBool,
+							Optional: true,
+							ForceNew: true,
+						},
+
+						"azure_file": {
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"share_name": {
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+
+									"storage_account_name": {
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+
+									"storage_account_key": {
+										Type:      schema.TypeString,
+										Required:  true,
+										ForceNew:  true,
+										Sensitive: true,
+									},
+
+									"read_only": {
+										Type:     schema.TypeBool,
+										Optional: true,
+										ForceNew: true,
+									},
+								},
+							},
+						},
+
+						"git_repo": {
+							Type:     schema.TypeList,
+							Optional: true,
+							ForceNew: true,
+							MaxItems: 1,
+							Elem: &schema.Resource{
+								Schema: map[string]*schema.Schema{
+									"repository": {
+										Type:     schema.TypeString,
+										Required: true,
+										ForceNew: true,
+									},
+
+									"directory": {
+										Type:     schema.TypeString,
+										Optional: true,
+										ForceNew: true,
+									},
+
+									"revision": {
+										Type:     schema.TypeString,
+										Optional: true,
+										ForceNew: true,
+									},
+								},
+							},
+						},
+
+						"secret": {
+							Type:      schema.TypeMap,
+							Optional:  true,
+							ForceNew:  true,
+							Sensitive: true,
+						},
 					},
 				},
 			},
@@ -148,20 +354,46 @@ func resourceArmContainerGroupCreate(d *schema.ResourceData, meta interface{}) e
 	IPAddressType := d.Get("ip_address_type").(string)
 	tags := d.Get("tags").(map[string]interface{})
 
+	networkProfileId := d.Get("network_profile_id").(string)
+	if strings.EqualFold(IPAddressType, "Private") && networkProfileId == "" {
+		return fmt.Errorf("`network_profile_id` must be specified when `ip_address_type` is set to `Private`")
+	}
+
 	containers, containerGroupPorts := expandContainerGroupContainers(d)
 
-	containerGroup := containerinstance.ContainerGroup{
-		Name:     &name,
-		Location: &location,
-		Tags:     expandTags(tags),
-		ContainerGroupProperties: &containerinstance.ContainerGroupProperties{
-			Containers: containers,
-			IPAddress: &containerinstance.IPAddress{
-				Type:  &IPAddressType,
-				Ports: containerGroupPorts,
-			},
-			OsType: containerinstance.OperatingSystemTypes(OSType),
+	containerGroupProperties := containerinstance.ContainerGroupProperties{
+		Containers: containers,
+		IPAddress: &containerinstance.IPAddress{
+			Type:  &IPAddressType,
+			Ports: containerGroupPorts,
 		},
+		OsType:        containerinstance.OperatingSystemTypes(OSType),
+		RestartPolicy: containerinstance.ContainerGroupRestartPolicy(d.Get("restart_policy").(string)),
+	}
+
+	if dnsNameLabel := d.Get("dns_name_label").(string); dnsNameLabel != "" {
+		containerGroupProperties.IPAddress.DNSNameLabel = &dnsNameLabel
+	}
+
+	if volumes := expandContainerGroupVolumes(d); len(*volumes) > 0 {
+		containerGroupProperties.Volumes = volumes
+	}
+
+	if credentials := expandContainerImageRegistryCredentials(d); len(*credentials) > 0 {
+		containerGroupProperties.ImageRegistryCredentials = credentials
+	}
+
+	if networkProfileId != "" {
+		containerGroupProperties.NetworkProfile = &containerinstance.ContainerGroupNetworkProfile{
+			ID: utils.String(networkProfileId),
+		}
+	}
+
+	containerGroup := containerinstance.ContainerGroup{
+		Name:                     &name,
+		Location:                 &location,
+		Tags:                     expandTags(tags),
+		ContainerGroupProperties: &containerGroupProperties,
 	}
 
 	_, err := containerGroupsClient.CreateOrUpdate(resGroup, name, containerGroup)
@@ -180,6 +412,19 @@ func resourceArmContainerGroupCreate(d *schema.ResourceData, meta interface{}) e
 
 	d.SetId(*read.ID)
 
+	log.Printf("[DEBUG] Waiting for Container Group %q (Resource Group %q) to be provisioned", name, resGroup)
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"Creating", "Pending", "Updating"},
+		Target:     []string{"Succeeded"},
+		Refresh:    containerGroupStateRefreshFunc(containerGroupsClient, resGroup, name),
+		Timeout:    d.Timeout(schema.TimeoutCreate),
+		MinTimeout: 15 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for Container Group %q (Resource Group %q) to become available: %+v", name, resGroup, err)
+	}
+
 	return resourceArmContainerGroupRead(d, meta)
 }
 func resourceArmContainerGroupRead(d *schema.ResourceData, meta interface{}) error {
@@ -207,14 +452,26 @@ func resourceArmContainerGroupRead(d *schema.ResourceData, meta interface{}) err
 	flattenAndSetTags(d, resp.Tags)
 
 	d.Set("os_type", string(resp.OsType))
+	d.Set("restart_policy", string(resp.RestartPolicy))
 	if address := resp.IPAddress; address != nil {
 		d.Set("ip_address_type", address.Type)
 		d.Set("ip_address", address.IP)
+		d.Set("dns_name_label", address.DNSNameLabel)
+		d.Set("fqdn", address.Fqdn)
+	}
+
+	networkProfileId := ""
+	if profile := resp.NetworkProfile; profile != nil && profile.ID != nil {
+		networkProfileId = *profile.ID
 	}
+	d.Set("network_profile_id", networkProfileId)
 
-	containerConfigs := flattenContainerGroupContainers(resp.Containers)
+	containerConfigs := flattenContainerGroupContainers(resp.Containers, resp.IPAddress)
 	d.Set("container", containerConfigs)
 
+	d.Set("volume", flattenContainerGroupVolumes(d, resp.Volumes))
+	d.Set("image_registry_credential", flattenContainerImageRegistryCredentials(d, resp.ImageRegistryCredentials))
+
 	return nil
 }
 
@@ -240,10 +497,23 @@ func resourceArmContainerGroupDelete(d *schema.ResourceData, meta interface{}) e
 		return err
 	}
 
+	log.Printf("[DEBUG] Waiting for Container Group %q (Resource Group %q) to be deleted", name, resGroup)
+	stateConf := &resource.StateChangeConf{
+		Pending:    []string{"Succeeded", "Deleting"},
+		Target:     []string{"Deleted"},
+		Refresh:    containerGroupStateRefreshFunc(containterGroupsClient, resGroup, name),
+		Timeout:    d.Timeout(schema.TimeoutDelete),
+		MinTimeout: 15 * time.Second,
+	}
+
+	if _, err := stateConf.WaitForState(); err != nil {
+		return fmt.Errorf("Error waiting for Container Group %q (Resource Group %q) to be deleted: %+v", name, resGroup, err)
+	}
+
 	return nil
 }
 
-func flattenContainerGroupContainers(containers *[]containerinstance.Container) []interface{} {
+func flattenContainerGroupContainers(containers *[]containerinstance.Container, groupIpAddress *containerinstance.IPAddress) []interface{} {
 
 	containerConfigs := make([]interface{}, 0, len(*containers))
 	for _, container := range *containers {
@@ -255,10 +525,17 @@ func flattenContainerGroupContainers(containers *[]containerinstance.Container)
 		containerConfig["cpu"] = *resourceRequests.CPU
 		containerConfig["memory"] = *resourceRequests.MemoryInGB
 
-		if len(*container.Ports) > 0 {
-			containerConfig["port"] = *(*container.Ports)[0].Port
+		if container.Ports != nil {
+			containerConfig["ports"] = flattenContainerPorts(container.Ports, groupIpAddress)
+		}
+
+		if container.VolumeMounts != nil {
+			containerConfig["volume_mount"] = flattenContainerVolumeMounts(container.VolumeMounts)
+		}
+
+		if container.Command != nil {
+			containerConfig["commands"] = *container.Command
 		}
-		// protocol isn't returned in container config
 
 		containerConfigs = append(containerConfigs, containerConfig)
 	}
@@ -266,6 +543,237 @@ func flattenContainerGroupContainers(containers *[]containerinstance.Container)
 	return containerConfigs
 }
 
+// flattenContainerPorts builds the per-container `ports` blocks. The container-level port only ever carries
+// a port number - `protocol` is exclusively a container *group* concept - so it's recovered here by matching
+// the port number against the group's own (protocol-bearing) ports.
+func flattenContainerPorts(ports *[]containerinstance.ContainerPort, groupIpAddress *containerinstance.IPAddress) []interface{} {
+	groupProtocols := make(map[int32]containerinstance.ContainerGroupNetworkProtocol)
+	if groupIpAddress != nil && groupIpAddress.Ports != nil {
+		for _, groupPort := range *groupIpAddress.Ports {
+			if groupPort.Port != nil {
+				groupProtocols[*groupPort.Port] = groupPort.Protocol
+			}
+		}
+	}
+
+	portConfigs := make([]interface{}, 0, len(*ports))
+	for _, port := range *ports {
+		protocol := ""
+		if port.Port != nil {
+			if groupProtocol, ok := groupProtocols[*port.Port]; ok {
+				protocol = strings.ToLower(string(groupProtocol))
+			}
+		}
+
+		portConfigs = append(portConfigs, map[string]interface{}{
+			"port":     int(*port.Port),
+			"protocol": protocol,
+		})
+	}
+
+	return portConfigs
+}
+
+func flattenContainerVolumeMounts(volumeMounts *[]containerinstance.VolumeMount) []interface{} {
+	volumeMountConfigs := make([]interface{}, 0, len(*volumeMounts))
+	for _, volumeMount := range *volumeMounts {
+		volumeMountConfig := make(map[string]interface{})
+		volumeMountConfig["name"] = *volumeMount.Name
+		volumeMountConfig["mount_path"] = *volumeMount.MountPath
+
+		if volumeMount.ReadOnly != nil {
+			volumeMountConfig["read_only"] = *volumeMount.ReadOnly
+		}
+
+		volumeMountConfigs = append(volumeMountConfigs, volumeMountConfig)
+	}
+
+	return volumeMountConfigs
+}
+
+func expandContainerVolumeMounts(data map[string]interface{}) *[]containerinstance.VolumeMount {
+	volumeMountsConfig := data["volume_mount"].([]interface{})
+	volumeMounts := make([]containerinstance.VolumeMount, 0, len(volumeMountsConfig))
+
+	for _, volumeMountConfig := range volumeMountsConfig {
+		volumeMountData := volumeMountConfig.(map[string]interface{})
+		name := volumeMountData["name"].(string)
+		mountPath := volumeMountData["mount_path"].(string)
+		readOnly := volumeMountData["read_only"].(bool)
+
+		volumeMounts = append(volumeMounts, containerinstance.VolumeMount{
+			Name:      &name,
+			MountPath: &mountPath,
+			ReadOnly:  &readOnly,
+		})
+	}
+
+	return &volumeMounts
+}
+
+func expandContainerGroupVolumes(d *schema.ResourceData) *[]containerinstance.Volume {
+	volumesConfig := d.Get("volume").([]interface{})
+	volumes := make([]containerinstance.Volume, 0, len(volumesConfig))
+
+	for _, volumeConfig := range volumesConfig {
+		data := volumeConfig.(map[string]interface{})
+		name := data["name"].(string)
+
+		volume := containerinstance.Volume{
+			Name: &name,
+		}
+
+		if data["empty_dir"].(bool) {
+			volume.EmptyDir = map[string]interface{}{}
+		}
+
+		if azureFileConfigs := data["azure_file"].([]interface{}); len(azureFileConfigs) > 0 {
+			azureFileData := azureFileConfigs[0].(map[string]interface{})
+
+			shareName := azureFileData["share_name"].(string)
+			storageAccountName := azureFileData["storage_account_name"].(string)
+			storageAccountKey := azureFileData["storage_account_key"].(string)
+			readOnly := azureFileData["read_only"].(bool)
+
+			volume.AzureFile = &containerinstance.AzureFileVolume{
+				ShareName:          &shareName,
+				StorageAccountName: &storageAccountName,
+				StorageAccountKey:  &storageAccountKey,
+				ReadOnly:           &readOnly,
+			}
+		}
+
+		if gitRepoConfigs := data["git_repo"].([]interface{}); len(gitRepoConfigs) > 0 {
+			gitRepoData := gitRepoConfigs[0].(map[string]interface{})
+
+			repository := gitRepoData["repository"].(string)
+			directory := gitRepoData["directory"].(string)
+			revision := gitRepoData["revision"].(string)
+
+			gitRepo := containerinstance.GitRepoVolume{
+				Repository: &repository,
+			}
+
+			if directory != "" {
+				gitRepo.Directory = &directory
+			}
+
+			if revision != "" {
+				gitRepo.Revision = &revision
+			}
+
+			volume.GitRepo = &gitRepo
+		}
+
+		if secretConfig := data["secret"].(map[string]interface{}); len(secretConfig) > 0 {
+			secret := make(map[string]*string)
+			for k, v := range secretConfig {
+				value := v.(string)
+				secret[k] = &value
+			}
+			volume.Secret = &secret
+		}
+
+		volumes = append(volumes, volume)
+	}
+
+	return &volumes
+}
+
+func flattenContainerGroupVolumes(d *schema.ResourceData, volumes *[]containerinstance.Volume) []interface{} {
+	volumeConfigs := make([]interface{}, 0)
+	if volumes == nil {
+		return volumeConfigs
+	}
+
+	for i, volume := range *volumes {
+		volumeConfig := make(map[string]interface{})
+		volumeConfig["name"] = *volume.Name
+
+		if volume.EmptyDir != nil {
+			volumeConfig["empty_dir"] = true
+		}
+
+		if azureFile := volume.AzureFile; azureFile != nil {
+			// storage_account_key isn't returned by the API - preserve the value from config
+			storageAccountKey := d.Get(fmt.Sprintf("volume.%d.azure_file.0.storage_account_key", i)).(string)
+
+			azureFileConfig := map[string]interface{}{
+				"share_name":           *azureFile.ShareName,
+				"storage_account_name": *azureFile.StorageAccountName,
+				"storage_account_key":  storageAccountKey,
+			}
+			if azureFile.ReadOnly != nil {
+				azureFileConfig["read_only"] = *azureFile.ReadOnly
+			}
+			volumeConfig["azure_file"] = []interface{}{azureFileConfig}
+		}
+
+		if gitRepo := volume.GitRepo; gitRepo != nil {
+			gitRepoConfig := map[string]interface{}{
+				"repository": *gitRepo.Repository,
+			}
+			if gitRepo.Directory != nil {
+				gitRepoConfig["directory"] = *gitRepo.Directory
+			}
+			if gitRepo.Revision != nil {
+				gitRepoConfig["revision"] = *gitRepo.Revision
+			}
+			volumeConfig["git_repo"] = []interface{}{gitRepoConfig}
+		}
+
+		if volume.Secret != nil {
+			// secret values aren't returned by the API - preserve them from config
+			volumeConfig["secret"] = d.Get(fmt.Sprintf("volume.%d.secret", i))
+		}
+
+		volumeConfigs = append(volumeConfigs, volumeConfig)
+	}
+
+	return volumeConfigs
+}
+
+func expandContainerImageRegistryCredentials(d *schema.ResourceData) *[]containerinstance.ImageRegistryCredential {
+	credsConfig := d.Get("image_registry_credential").([]interface{})
+	credentials := make([]containerinstance.ImageRegistryCredential, 0, len(credsConfig))
+
+	for _, credConfig := range credsConfig {
+		data := credConfig.(map[string]interface{})
+
+		server := data["server"].(string)
+		username := data["username"].(string)
+		password := data["password"].(string)
+
+		credentials = append(credentials, containerinstance.ImageRegistryCredential{
+			Server:   &server,
+			Username: &username,
+			Password: &password,
+		})
+	}
+
+	return &credentials
+}
+
+func flattenContainerImageRegistryCredentials(d *schema.ResourceData, credentials *[]containerinstance.ImageRegistryCredential) []interface{} {
+	credConfigs := make([]interface{}, 0)
+	if credentials == nil {
+		return credConfigs
+	}
+
+	for i, credential := range *credentials {
+		// password isn't returned by the API - preserve the value from config
+		password := d.Get(fmt.Sprintf("image_registry_credential.%d.password", i)).(string)
+
+		credConfigs = append(credConfigs, map[string]interface{}{
+			"server":   *credential.Server,
+			"username": *credential.Username,
+			"password": password,
+		})
+	}
+
+	return credConfigs
+}
+
 func expandContainerGroupContainers(d *schema.ResourceData) (*[]containerinstance.Container, *[]containerinstance.Port) {
 	containersConfig := d.Get("container").([]interface{})
 	containers := make([]containerinstance.Container, 0, len(containersConfig))
@@ -293,29 +801,45 @@ func expandContainerGroupContainers(d *schema.ResourceData) (*[]containerinstanc
 			},
 		}
 
-		if v, _ := data["port"]; v != 0 {
-			port := int32(v.(int))
+		if commandsConfig := data["commands"].([]interface{}); len(commandsConfig) > 0 {
+			commands := make([]string, 0, len(commandsConfig))
+			for _, c := range commandsConfig {
+				commands = append(commands, c.(string))
+			}
+			container.Command = &commands
+		}
+
+		portsConfig := data["ports"].([]interface{})
+		containerPorts := make([]containerinstance.ContainerPort, 0, len(portsConfig))
+		for _, portConfig := range portsConfig {
+			portData := portConfig.(map[string]interface{})
+			port := int32(portData["port"].(int))
 
 			// container port (port number)
-			containerPort := containerinstance.ContainerPort{
+			containerPorts = append(containerPorts, containerinstance.ContainerPort{
 				Port: &port,
-			}
-
-			container.Ports = &[]containerinstance.ContainerPort{containerPort}
+			})
 
 			// container group port (port number + protocol)
 			containerGroupPort := containerinstance.Port{
 				Port: &port,
 			}
 
-			if v, ok := data["protocol"]; ok {
-				protocol := v.(string)
-				containerGroupPort.Protocol = containerinstance.ContainerGroupNetworkProtocol(strings.ToUpper(protocol))
+			if protocol, ok := portData["protocol"]; ok && protocol.(string) != "" {
+				containerGroupPort.Protocol = containerinstance.ContainerGroupNetworkProtocol(strings.ToUpper(protocol.(string)))
 			}
 
 			containerGroupPorts = append(containerGroupPorts, containerGroupPort)
 		}
 
+		if len(containerPorts) > 0 {
+			container.Ports = &containerPorts
+		}
+
+		if volumeMounts := expandContainerVolumeMounts(data); len(*volumeMounts) > 0 {
+			container.VolumeMounts = volumeMounts
+		}
+
 		containers = append(containers, container)
 	}
 
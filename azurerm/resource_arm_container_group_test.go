@@ -0,0 +1,130 @@
+package azurerm
+
+import (
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/arm/containerinstance"
+	"github.com/hashicorp/terraform/helper/schema"
+)
+
+func TestExpandContainerVolumeMounts(t *testing.T) {
+	data := map[string]interface{}{
+		"volume_mount": []interface{}{
+			map[string]interface{}{
+				"name":       "logs",
+				"mount_path": "/var/log",
+				"read_only":  true,
+			},
+		},
+	}
+
+	result := expandContainerVolumeMounts(data)
+	if len(*result) != 1 {
+		t.Fatalf("expected 1 volume mount but got %d", len(*result))
+	}
+
+	mount := (*result)[0]
+	if *mount.Name != "logs" {
+		t.Fatalf("expected `Name` to be %q but got %q", "logs", *mount.Name)
+	}
+	if *mount.MountPath != "/var/log" {
+		t.Fatalf("expected `MountPath` to be %q but got %q", "/var/log", *mount.MountPath)
+	}
+	if !*mount.ReadOnly {
+		t.Fatalf("expected `ReadOnly` to be true")
+	}
+}
+
+func TestFlattenContainerVolumeMounts(t *testing.T) {
+	name := "logs"
+	mountPath := "/var/log"
+	readOnly := true
+
+	input := &[]containerinstance.VolumeMount{
+		{
+			Name:      &name,
+			MountPath: &mountPath,
+			ReadOnly:  &readOnly,
+		},
+	}
+
+	result := flattenContainerVolumeMounts(input)
+	if len(result) != 1 {
+		t.Fatalf("expected 1 volume mount but got %d", len(result))
+	}
+
+	mount := result[0].(map[string]interface{})
+	if mount["name"] != "logs" {
+		t.Fatalf("expected `name` to be %q but got %q", "logs", mount["name"])
+	}
+	if mount["mount_path"] != "/var/log" {
+		t.Fatalf("expected `mount_path` to be %q but got %q", "/var/log", mount["mount_path"])
+	}
+	if mount["read_only"] != true {
+		t.Fatalf("expected `read_only` to be true")
+	}
+}
+
+func TestExpandContainerGroupVolumes_azureFile(t *testing.T) {
+	raw := map[string]interface{}{
+		"volume": []interface{}{
+			map[string]interface{}{
+				"name":      "files",
+				"empty_dir": false,
+				"azure_file": []interface{}{
+					map[string]interface{}{
+						"share_name":           "myshare",
+						"storage_account_name": "mystorageaccount",
+						"storage_account_key":  "abc123",
+						"read_only":            false,
+					},
+				},
+				"git_repo": []interface{}{},
+				"secret":   map[string]interface{}{},
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceArmContainerGroup().Schema, raw)
+
+	result := expandContainerGroupVolumes(d)
+	if len(*result) != 1 {
+		t.Fatalf("expected 1 volume but got %d", len(*result))
+	}
+
+	volume := (*result)[0]
+	if *volume.Name != "files" {
+		t.Fatalf("expected `Name` to be %q but got %q", "files", *volume.Name)
+	}
+	if volume.AzureFile == nil {
+		t.Fatalf("expected `AzureFile` to be set")
+	}
+	if *volume.AzureFile.ShareName != "myshare" {
+		t.Fatalf("expected `ShareName` to be %q but got %q", "myshare", *volume.AzureFile.ShareName)
+	}
+}
+
+func TestExpandContainerGroupVolumes_emptyDir(t *testing.T) {
+	raw := map[string]interface{}{
+		"volume": []interface{}{
+			map[string]interface{}{
+				"name":       "scratch",
+				"empty_dir":  true,
+				"azure_file": []interface{}{},
+				"git_repo":   []interface{}{},
+				"secret":     map[string]interface{}{},
+			},
+		},
+	}
+
+	d := schema.TestResourceDataRaw(t, resourceArmContainerGroup().Schema, raw)
+
+	result := expandContainerGroupVolumes(d)
+	if len(*result) != 1 {
+		t.Fatalf("expected 1 volume but got %d", len(*result))
+	}
+
+	if (*result)[0].EmptyDir == nil {
+		t.Fatalf("expected `EmptyDir` to be set")
+	}
+}
@@ -0,0 +1,86 @@
+package azurerm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/arm/containerinstance"
+	"github.com/hashicorp/terraform/helper/resource"
+	"github.com/terraform-providers/terraform-provider-azurerm/azurerm/utils"
+)
+
+// containerGroupStateRefreshFunc returns a resource.StateRefreshFunc that polls the
+// provisioning state of a Container Group, for use with a resource.StateChangeConf
+// while waiting for a create or delete to finish propagating through the RP.
+//
+// A `Failed` provisioning state is terminal - rather than let the caller's Pending/Target
+// lists time out waiting for a state that will never arrive, it's surfaced immediately as an
+// error, along with the instance-view state of each container so the failure (e.g. an image
+// pull failure or a crashing container) is actually debuggable from the `apply` output.
+func containerGroupStateRefreshFunc(client containerinstance.ContainerGroupsClient, resourceGroup string, name string) resource.StateRefreshFunc {
+	return func() (interface{}, string, error) {
+		resp, err := client.Get(resourceGroup, name)
+		if err != nil {
+			if utils.ResponseWasNotFound(resp.Response) {
+				return resp, "Deleted", nil
+			}
+
+			return nil, "", err
+		}
+
+		if props := resp.ContainerGroupProperties; props != nil {
+			if state := props.ProvisioningState; state != nil {
+				if *state == "Failed" {
+					return resp, *state, fmt.Errorf("Container Group %q (Resource Group %q) entered a Failed provisioning state: %s", name, resourceGroup, containerInstanceViewSummary(props))
+				}
+
+				return resp, *state, nil
+			}
+		}
+
+		return resp, "Pending", nil
+	}
+}
+
+// containerInstanceViewSummary summarises the most recent instance-view state reported for each
+// container in a Container Group (e.g. `web: state=Terminated (CrashLoopBackOff)`), for inclusion in
+// the error returned when a Container Group fails to provision.
+func containerInstanceViewSummary(props *containerinstance.ContainerGroupProperties) string {
+	if props.Containers == nil {
+		return "no further detail was returned by the Container Instance API"
+	}
+
+	details := make([]string, 0)
+	for _, container := range *props.Containers {
+		if container.InstanceView == nil || container.InstanceView.CurrentState == nil {
+			continue
+		}
+
+		name := ""
+		if container.Name != nil {
+			name = *container.Name
+		}
+
+		state := container.InstanceView.CurrentState
+		detail := fmt.Sprintf("%s: state=%s", name, stringOrEmpty(state.State))
+		if state.DetailStatus != nil && *state.DetailStatus != "" {
+			detail = fmt.Sprintf("%s (%s)", detail, *state.DetailStatus)
+		}
+
+		details = append(details, detail)
+	}
+
+	if len(details) == 0 {
+		return "no further detail was returned by the Container Instance API"
+	}
+
+	return strings.Join(details, "; ")
+}
+
+func stringOrEmpty(input *string) string {
+	if input == nil {
+		return ""
+	}
+
+	return *input
+}